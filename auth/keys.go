@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Signing keys are configured as a list of "kid:secret" pairs so a new key can be
+// introduced while tokens signed with the previous one stay valid:
+//
+//	JWT_KEYS=2025-01:old-secret,2025-06:new-secret
+//	JWT_ACTIVE_KEY_ID=2025-06
+//
+// A lone JWT_SECRET is accepted as a single key with id "default".
+// Secrets shorter than this are accepted but logged as weak.
+const minSecretLength = 32
+
+type keySet struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+var signingKeys *keySet
+
+// Init loads the signing keys, password hashing parameters and secret encryption key
+// from the environment.
+// It must run after the .env file is loaded, and the server must not start when it fails.
+func Init() error {
+	ks, err := loadKeys(os.Getenv("JWT_KEYS"), os.Getenv("JWT_ACTIVE_KEY_ID"), os.Getenv("JWT_SECRET"))
+	if err != nil {
+		return err
+	}
+	params, err := loadPasswordParams()
+	if err != nil {
+		return err
+	}
+	sc, err := loadSecretCipher(os.Getenv("TOTP_ENCRYPTION_KEY"))
+	if err != nil {
+		return err
+	}
+	signingKeys = ks
+	passwordParams = params
+	secretCipher = sc
+	return nil
+}
+
+func loadKeys(list, activeID, single string) (*keySet, error) {
+	ks := &keySet{keys: map[string][]byte{}}
+
+	if strings.TrimSpace(list) == "" {
+		if single == "" {
+			return nil, fmt.Errorf("no JWT signing key configured: set JWT_KEYS and JWT_ACTIVE_KEY_ID, or JWT_SECRET")
+		}
+		list = "default:" + single
+		if activeID == "" {
+			activeID = "default"
+		}
+	}
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(entry, ":")
+		if !ok || kid == "" || secret == "" {
+			return nil, fmt.Errorf("invalid JWT_KEYS entry %q, expected kid:secret", entry)
+		}
+		if _, dup := ks.keys[kid]; dup {
+			return nil, fmt.Errorf("duplicate JWT key id %q", kid)
+		}
+		if len(secret) < minSecretLength {
+			log.Printf("JWT key %q is shorter than %d characters, consider a longer secret", kid, minSecretLength)
+		}
+		ks.keys[kid] = []byte(secret)
+	}
+
+	if activeID == "" {
+		return nil, fmt.Errorf("JWT_ACTIVE_KEY_ID is required when JWT_KEYS is set")
+	}
+	if _, ok := ks.keys[activeID]; !ok {
+		return nil, fmt.Errorf("JWT_ACTIVE_KEY_ID %q is not in JWT_KEYS", activeID)
+	}
+	ks.activeID = activeID
+
+	return ks, nil
+}
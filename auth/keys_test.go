@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadKeys(t *testing.T) {
+	tests := []struct {
+		name       string
+		list       string
+		activeID   string
+		single     string
+		wantActive string
+		wantKeys   []string
+		wantErr    string
+	}{
+		{
+			name:       "rotation list",
+			list:       "2025-01:old-secret,2025-06:new-secret",
+			activeID:   "2025-06",
+			wantActive: "2025-06",
+			wantKeys:   []string{"2025-01", "2025-06"},
+		},
+		{
+			name:       "spaces and empty entries are ignored",
+			list:       " 2025-01:old-secret , ,2025-06:new-secret ",
+			activeID:   "2025-01",
+			wantActive: "2025-01",
+			wantKeys:   []string{"2025-01", "2025-06"},
+		},
+		{
+			name:       "secret may contain a colon",
+			list:       "k1:abc:def",
+			activeID:   "k1",
+			wantActive: "k1",
+			wantKeys:   []string{"k1"},
+		},
+		{
+			name:       "lone JWT_SECRET",
+			single:     "legacy-secret",
+			wantActive: "default",
+			wantKeys:   []string{"default"},
+		},
+		{
+			name:       "JWT_KEYS wins over JWT_SECRET",
+			list:       "k1:secret",
+			activeID:   "k1",
+			single:     "legacy-secret",
+			wantActive: "k1",
+			wantKeys:   []string{"k1"},
+		},
+		{name: "nothing configured", wantErr: "no JWT signing key configured"},
+		{name: "entry without secret", list: "k1", activeID: "k1", wantErr: "expected kid:secret"},
+		{name: "entry with empty kid", list: ":secret", activeID: "k1", wantErr: "expected kid:secret"},
+		{name: "duplicate kid", list: "k1:a,k1:b", activeID: "k1", wantErr: "duplicate JWT key id"},
+		{name: "missing active id", list: "k1:secret", wantErr: "JWT_ACTIVE_KEY_ID is required"},
+		{name: "unknown active id", list: "k1:secret", activeID: "k2", wantErr: "is not in JWT_KEYS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := loadKeys(tt.list, tt.activeID, tt.single)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadKeys() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadKeys() error = %v", err)
+			}
+			if ks.activeID != tt.wantActive {
+				t.Errorf("activeID = %q, want %q", ks.activeID, tt.wantActive)
+			}
+			if len(ks.keys) != len(tt.wantKeys) {
+				t.Errorf("got %d keys, want %d", len(ks.keys), len(tt.wantKeys))
+			}
+			for _, kid := range tt.wantKeys {
+				if _, ok := ks.keys[kid]; !ok {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
+
+// Tokens signed before a rotation must keep verifying while their key is configured
+func TestVerifyAfterRotation(t *testing.T) {
+	old, err := loadKeys("k1:first-secret-of-at-least-32-chars", "k1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := loadKeys("k1:first-secret-of-at-least-32-chars,k2:second-secret-of-at-least-32-chars", "k2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped, err := loadKeys("k2:second-secret-of-at-least-32-chars", "k2", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(ks *keySet) { signingKeys = ks }(signingKeys)
+
+	signingKeys = old
+	token, err := Issue("42", AudienceUser, time.Minute, Claims{SessionID: "s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		keys   *keySet
+		aud    string
+		wantOK bool
+	}{
+		{"same keys", old, AudienceUser, true},
+		{"old key still listed", rotated, AudienceUser, true},
+		{"old key removed", dropped, AudienceUser, false},
+		{"other audience", rotated, AudienceAdmin, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signingKeys = tt.keys
+			claims, err := Verify(token, tt.aud)
+			if (err == nil) != tt.wantOK {
+				t.Fatalf("Verify() error = %v, want ok = %v", err, tt.wantOK)
+			}
+			if tt.wantOK && (claims.Subject != "42" || claims.SessionID != "s1") {
+				t.Errorf("claims = %+v", claims)
+			}
+		})
+	}
+}
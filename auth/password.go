@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// New password hashes use argon2id in the PHC string format, so the algorithm and
+// its parameters travel with the hash:
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+//
+// bcrypt hashes ($2a$, $2b$, $2y$) written before the switch still verify and are
+// reported as needing a rehash. Parameters come from the environment:
+//
+//	ARGON2_MEMORY_KB (default 65536), ARGON2_ITERATIONS (default 3), ARGON2_PARALLELISM (default 2)
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var passwordParams = argon2Params{memory: 64 * 1024, iterations: 3, parallelism: 2}
+
+var ErrUnknownHashFormat = errors.New("auth: unknown password hash format")
+
+func loadPasswordParams() (argon2Params, error) {
+	p := passwordParams
+	for _, setting := range []struct {
+		env string
+		max uint64
+		set func(uint64)
+	}{
+		{"ARGON2_MEMORY_KB", 4 * 1024 * 1024, func(v uint64) { p.memory = uint32(v) }},
+		{"ARGON2_ITERATIONS", 100, func(v uint64) { p.iterations = uint32(v) }},
+		{"ARGON2_PARALLELISM", 255, func(v uint64) { p.parallelism = uint8(v) }},
+	} {
+		raw := os.Getenv(setting.env)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || v == 0 || v > setting.max {
+			return p, fmt.Errorf("%s must be a number between 1 and %d", setting.env, setting.max)
+		}
+		setting.set(v)
+	}
+	if p.memory < 8*uint32(p.parallelism) {
+		return p, fmt.Errorf("ARGON2_MEMORY_KB must be at least 8 times ARGON2_PARALLELISM")
+	}
+	return p, nil
+}
+
+// HashPassword hashes a password with argon2id and the configured parameters
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := passwordParams
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword reports whether password matches hash. needsRehash is true when the
+// password matched but the hash uses an older algorithm or weaker parameters than
+// the current ones, so the caller should store a fresh HashPassword result.
+func VerifyPassword(hash, password string) (ok bool, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return verifyArgon2id(hash, password)
+
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, false, nil
+		} else if err != nil {
+			return false, false, err
+		}
+		return true, true, nil
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
+
+func verifyArgon2id(hash, password string) (bool, bool, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, ErrUnknownHashFormat
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return false, false, nil
+	}
+
+	current := passwordParams
+	weaker := p.memory < current.memory || p.iterations < current.iterations ||
+		p.parallelism < current.parallelism || len(key) < argon2KeyLength
+	return true, weaker, nil
+}
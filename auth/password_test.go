@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Small parameters keep the tests fast; only their relative strength matters
+var testPasswordParams = argon2Params{memory: 64, iterations: 2, parallelism: 1}
+
+func withPasswordParams(t *testing.T, p argon2Params) {
+	t.Helper()
+	previous := passwordParams
+	passwordParams = p
+	t.Cleanup(func() { passwordParams = previous })
+}
+
+func TestHashPasswordFormat(t *testing.T) {
+	withPasswordParams(t, testPasswordParams)
+
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=2,p=1$") {
+		t.Errorf("HashPassword() = %q, want the PHC argon2id format with the current parameters", hash)
+	}
+	if parts := strings.Split(hash, "$"); len(parts) != 6 {
+		t.Errorf("HashPassword() has %d parts, want 6", len(parts))
+	}
+	if again, _ := HashPassword("correct horse"); again == hash {
+		t.Error("two hashes of the same password are identical, the salt is not random")
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	withPasswordParams(t, testPasswordParams)
+	current, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withPasswordParams(t, argon2Params{memory: 32, iterations: 1, parallelism: 1})
+	weaker, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withPasswordParams(t, argon2Params{memory: 128, iterations: 3, parallelism: 2})
+	stronger, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withPasswordParams(t, testPasswordParams)
+	parts := strings.Split(current, "$")
+
+	tests := []struct {
+		name       string
+		hash       string
+		password   string
+		wantOK     bool
+		wantRehash bool
+		wantErr    error
+	}{
+		{name: "argon2id match", hash: current, password: "correct horse", wantOK: true},
+		{name: "argon2id mismatch", hash: current, password: "wrong horse"},
+		{name: "weaker parameters", hash: weaker, password: "correct horse", wantOK: true, wantRehash: true},
+		{name: "weaker parameters, wrong password", hash: weaker, password: "wrong horse"},
+		{name: "stronger parameters", hash: stronger, password: "correct horse", wantOK: true},
+		{
+			name: "shorter key", password: "correct horse", wantOK: true, wantRehash: true,
+			hash: shortKeyHash(t, "correct horse"),
+		},
+		{name: "bcrypt match", hash: string(legacy), password: "correct horse", wantOK: true, wantRehash: true},
+		{name: "bcrypt mismatch", hash: string(legacy), password: "wrong horse"},
+		{name: "unknown format", hash: "5f4dcc3b5aa765d61d8327deb882cf99", password: "password", wantErr: ErrUnknownHashFormat},
+		{name: "empty hash", hash: "", password: "password", wantErr: ErrUnknownHashFormat},
+		{name: "missing parts", hash: strings.Join(parts[:5], "$"), password: "correct horse", wantErr: ErrUnknownHashFormat},
+		{
+			name: "other argon2 version", password: "correct horse", wantErr: ErrUnknownHashFormat,
+			hash: strings.Replace(current, "$v=19$", "$v=16$", 1),
+		},
+		{
+			name: "unreadable parameters", password: "correct horse", wantErr: ErrUnknownHashFormat,
+			hash: strings.Replace(current, "m=64,t=2,p=1", "m=64;t=2;p=1", 1),
+		},
+		{
+			name: "salt not base64", password: "correct horse", wantErr: ErrUnknownHashFormat,
+			hash: strings.Join([]string{"", parts[1], parts[2], parts[3], "!!!", parts[5]}, "$"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, rehash, err := VerifyPassword(tt.hash, tt.password)
+			if err != tt.wantErr {
+				t.Fatalf("VerifyPassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || rehash != tt.wantRehash {
+				t.Errorf("VerifyPassword() = %v, %v, want %v, %v", ok, rehash, tt.wantOK, tt.wantRehash)
+			}
+		})
+	}
+}
+
+// shortKeyHash builds a hash with the current parameters but a 16-byte key
+func shortKeyHash(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := HashPassword(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(hash, "$")
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := passwordParams
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, 16)
+	parts[5] = base64.RawStdEncoding.EncodeToString(key)
+	return strings.Join(parts, "$")
+}
+
+func TestLoadPasswordParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    argon2Params
+		wantErr string
+	}{
+		{name: "defaults", want: argon2Params{memory: 64 * 1024, iterations: 3, parallelism: 2}},
+		{
+			name: "overrides",
+			env:  map[string]string{"ARGON2_MEMORY_KB": "131072", "ARGON2_ITERATIONS": "4", "ARGON2_PARALLELISM": "4"},
+			want: argon2Params{memory: 131072, iterations: 4, parallelism: 4},
+		},
+		{name: "zero", env: map[string]string{"ARGON2_ITERATIONS": "0"}, wantErr: "ARGON2_ITERATIONS must be a number"},
+		{name: "not a number", env: map[string]string{"ARGON2_MEMORY_KB": "64MB"}, wantErr: "ARGON2_MEMORY_KB must be a number"},
+		{name: "above the maximum", env: map[string]string{"ARGON2_PARALLELISM": "256"}, wantErr: "ARGON2_PARALLELISM must be a number"},
+		{
+			name:    "memory too small for parallelism",
+			env:     map[string]string{"ARGON2_MEMORY_KB": "64", "ARGON2_PARALLELISM": "16"},
+			wantErr: "at least 8 times ARGON2_PARALLELISM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPasswordParams(t, argon2Params{memory: 64 * 1024, iterations: 3, parallelism: 2})
+			for _, env := range []string{"ARGON2_MEMORY_KB", "ARGON2_ITERATIONS", "ARGON2_PARALLELISM"} {
+				t.Setenv(env, tt.env[env])
+			}
+
+			got, err := loadPasswordParams()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadPasswordParams() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadPasswordParams() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("loadPasswordParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
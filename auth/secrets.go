@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Secrets the server has to read back, such as TOTP secrets, are stored encrypted
+// with AES-256-GCM under TOTP_ENCRYPTION_KEY, 32 random bytes in base64:
+//
+//	TOTP_ENCRYPTION_KEY=$(openssl rand -base64 32)
+//
+// Stored values look like enc1:<base64 of nonce and ciphertext>. The owner of the
+// secret (e.g. the admin id) is bound in as additional data, so a value copied to
+// another row does not decrypt.
+const encryptedSecretPrefix = "enc1:"
+
+var secretCipher cipher.AEAD
+
+var ErrSecretNotEncrypted = errors.New("auth: secret is stored in plain text")
+
+func loadSecretCipher(encoded string) (cipher.AEAD, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("no secret encryption key configured: set TOTP_ENCRYPTION_KEY to 32 random bytes in base64")
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("TOTP_ENCRYPTION_KEY must be 32 bytes in base64")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptSecret encrypts plain for storage; owner must be given again to decrypt it
+func EncryptSecret(plain, owner string) (string, error) {
+	if secretCipher == nil {
+		return "", ErrNotInitialized
+	}
+	nonce := make([]byte, secretCipher.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := secretCipher.Seal(nonce, nonce, []byte(plain), []byte(owner))
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret reverses EncryptSecret. A value stored before encryption was
+// introduced is returned as it is, together with ErrSecretNotEncrypted.
+func DecryptSecret(stored, owner string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedSecretPrefix) {
+		return stored, ErrSecretNotEncrypted
+	}
+	if secretCipher == nil {
+		return "", ErrNotInitialized
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, encryptedSecretPrefix))
+	if err != nil || len(sealed) < secretCipher.NonceSize() {
+		return "", errors.New("auth: malformed encrypted secret")
+	}
+	nonce, ciphertext := sealed[:secretCipher.NonceSize()], sealed[secretCipher.NonceSize():]
+	plain, err := secretCipher.Open(nil, nonce, ciphertext, []byte(owner))
+	if err != nil {
+		return "", errors.New("auth: encrypted secret does not decrypt with this key")
+	}
+	return string(plain), nil
+}
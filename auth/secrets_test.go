@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/cipher"
+	"strings"
+	"testing"
+)
+
+const testSecretKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+
+func TestLoadSecretCipher(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{"32 bytes", testSecretKey, false},
+		{"surrounding spaces", " " + testSecretKey + "\n", false},
+		{"missing", "", true},
+		{"not base64", "not base64!", true},
+		{"16 bytes", "MDEyMzQ1Njc4OWFiY2RlZg==", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadSecretCipher(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadSecretCipher() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptSecret(t *testing.T) {
+	sc, err := loadSecretCipher(testSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c cipher.AEAD) { secretCipher = c }(secretCipher)
+	secretCipher = sc
+
+	stored, err := EncryptSecret("JBSWY3DPEHPK3PXP", "admin-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, encryptedSecretPrefix) || strings.Contains(stored, "JBSWY3DPEHPK3PXP") {
+		t.Fatalf("EncryptSecret() = %q", stored)
+	}
+	if again, _ := EncryptSecret("JBSWY3DPEHPK3PXP", "admin-1"); again == stored {
+		t.Error("two encryptions of the same secret are identical")
+	}
+
+	flipped := []byte(stored)
+	if i := len(flipped) - 10; flipped[i] == 'A' {
+		flipped[i] = 'B'
+	} else {
+		flipped[i] = 'A'
+	}
+
+	tests := []struct {
+		name    string
+		stored  string
+		owner   string
+		want    string
+		wantErr bool
+	}{
+		{"same owner", stored, "admin-1", "JBSWY3DPEHPK3PXP", false},
+		{"other owner", stored, "admin-2", "", true},
+		{"tampered", string(flipped), "admin-1", "", true},
+		{"truncated", encryptedSecretPrefix + "AAAA", "admin-1", "", true},
+		{"plain text from before encryption", "JBSWY3DPEHPK3PXP", "admin-1", "JBSWY3DPEHPK3PXP", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecryptSecret(tt.stored, tt.owner)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("DecryptSecret() = %q, %v, want %q, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if _, err := DecryptSecret("JBSWY3DPEHPK3PXP", "admin-1"); err != ErrSecretNotEncrypted {
+		t.Errorf("plain text secret: error = %v, want ErrSecretNotEncrypted", err)
+	}
+}
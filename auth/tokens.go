@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const issuer = "models-backend"
+
+// Audiences keep tokens of one kind from being accepted where another is expected
+const (
+	AudienceUser           = "user"
+	AudienceAdmin          = "admin"
+	AudienceAdminChallenge = "admin_challenge" // password step passed, second factor pending
+	AudienceContactForm    = "contact_form"    // public contact form was shown, see handlers.ContactFormToken
+)
+
+var ErrNotInitialized = errors.New("auth: signing keys not loaded, call auth.Init first")
+
+// Claims is the payload of every token issued by this service
+type Claims struct {
+	SessionID string   `json:"sid,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	Purpose   string   `json:"purpose,omitempty"`
+	Challenge string   `json:"cid,omitempty"` // id of a single-use login challenge
+	jwt.RegisteredClaims
+}
+
+// UserID returns the subject of a user token as an int
+func (c *Claims) UserID() (int, error) {
+	return strconv.Atoi(c.Subject)
+}
+
+// Issue signs claims for one audience with the active key. Subject, audience,
+// issuer and times are filled in here.
+func Issue(subject, audience string, ttl time.Duration, claims Claims) (string, error) {
+	if signingKeys == nil {
+		return "", ErrNotInitialized
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = signingKeys.activeID
+	return token.SignedString(signingKeys.keys[signingKeys.activeID])
+}
+
+// Verify checks signature, expiry, issuer and audience, and returns the claims.
+// Any configured key can verify, so tokens signed before a rotation still work.
+func Verify(tokenString, audience string) (*Claims, error) {
+	if signingKeys == nil {
+		return nil, ErrNotInitialized
+	}
+
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := signingKeys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+	return claims, nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header value
+func BearerToken(header string) (string, bool) {
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == header || token == "" {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,95 @@
+package captcha
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewVerifier(t *testing.T) {
+	tests := []struct {
+		driver  string
+		secret  string
+		wantURL string // empty for the stubs
+		want    Verifier
+		wantErr string
+	}{
+		{driver: "", want: Stub(true)},
+		{driver: "pass", want: Stub(true)},
+		{driver: "fail", want: Stub(false)},
+		{driver: "recaptcha", secret: "s", wantURL: "https://www.google.com/recaptcha/api/siteverify"},
+		{driver: "hcaptcha", secret: "s", wantURL: "https://api.hcaptcha.com/siteverify"},
+		{driver: "turnstile", secret: "s", wantURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify"},
+		{driver: "turnstile", wantErr: "needs CAPTCHA_SECRET"},
+		{driver: "friendlycaptcha", secret: "s", wantErr: "unknown CAPTCHA_DRIVER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			v, err := newVerifier(tt.driver, tt.secret)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newVerifier() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newVerifier() error = %v", err)
+			}
+			if tt.wantURL == "" {
+				if v != tt.want {
+					t.Errorf("newVerifier() = %#v, want %#v", v, tt.want)
+				}
+				return
+			}
+			sv, ok := v.(*siteVerifier)
+			if !ok {
+				t.Fatalf("newVerifier() = %T, want *siteVerifier", v)
+			}
+			if sv.url != tt.wantURL || sv.secret != tt.secret {
+				t.Errorf("siteVerifier = %s with secret %q, want %s with %q", sv.url, sv.secret, tt.wantURL, tt.secret)
+			}
+		})
+	}
+}
+
+func TestSiteVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch {
+		case r.Form.Get("secret") != "secret" || r.Form.Get("remoteip") != "203.0.113.7":
+			w.WriteHeader(http.StatusBadRequest)
+		case r.Form.Get("response") == "broken":
+			w.Write([]byte("not json"))
+		case r.Form.Get("response") == "solved":
+			w.Write([]byte(`{"success": true}`))
+		default:
+			w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		secret  string
+		token   string
+		want    bool
+		wantErr bool
+	}{
+		{"solved", "secret", "solved", true, false},
+		{"rejected", "secret", "guessed", false, false},
+		{"no token", "secret", "", false, false},
+		{"unreadable answer", "secret", "broken", false, true},
+		{"provider error", "wrong", "solved", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &siteVerifier{url: server.URL, secret: tt.secret, client: server.Client()}
+			got, err := v.Verify(tt.token, "203.0.113.7")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Verify() = %v, %v, want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
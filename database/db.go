@@ -61,3 +61,40 @@ func (database *Database) InitDatabase() {
 }
 
 // key = value
+
+// FinishLegacyTalentMigration logs how much of the former models and hostesses tables
+// was copied into talents. Those tables stay as a backup until the server is started
+// with DROP_LEGACY_TALENT_TABLES=true, and even then only go once every row was copied.
+func FinishLegacyTalentMigration() {
+	rows, err := DB.Query(`
+		SELECT legacy_table, legacy_rows, copied_rows FROM legacy_talent_copies
+		WHERE to_regclass(legacy_table) IS NOT NULL
+		ORDER BY legacy_table
+	`)
+	if err != nil {
+		log.Println("Failed to read legacy talent copies:", err)
+		return
+	}
+	defer rows.Close()
+
+	pending := false
+	for rows.Next() {
+		var table string
+		var legacyRows, copiedRows int
+		if err := rows.Scan(&table, &legacyRows, &copiedRows); err != nil {
+			log.Println("Failed to read legacy talent copies:", err)
+			return
+		}
+		pending = true
+		log.Printf("Legacy talent table %s: %d of %d rows copied", table, copiedRows, legacyRows)
+	}
+	if !pending || os.Getenv("DROP_LEGACY_TALENT_TABLES") != "true" {
+		return
+	}
+
+	if _, err := DB.Exec(dropLegacyTalentTables); err != nil {
+		log.Println("Legacy talent tables kept:", err)
+		return
+	}
+	log.Println("Legacy talent tables dropped")
+}
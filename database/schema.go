@@ -15,141 +15,256 @@ func GetTableQueries() []string {
     		position TEXT,
     		created_at TIMESTAMP DEFAULT NOW()
 );`,
-		`CREATE TABLE IF NOT EXISTS models (
-		    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-			user_id INT REFERENCES 	users(userid) not null,
-		    first_name VARCHAR(100) NOT NULL,
-		    last_name VARCHAR(100) NOT NULL,
-		    username VARCHAR(50) UNIQUE NOT NULL,
-		    email VARCHAR(255) UNIQUE NOT NULL,
-		    whatsapp VARCHAR(20) NOT NULL,
-		    date_of_birth DATE NOT NULL,
-		    gender VARCHAR(10) NOT NULL CHECK (gender IN ('Female','Male','Other')),
-		    nationality VARCHAR(100) NOT NULL,
-			street VARCHAR(100) NOT NULL,
-			city VARCHAR(100) NOT NULL,
-		    residence_country VARCHAR(100) NOT NULL,
-		    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending','under_review','approved','rejected')),
-		    deleted BOOLEAN DEFAULT FALSE,
-		    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			registration_step INT DEFAULT 1
-);
-`, `CREATE TABLE IF NOT EXISTS model_measurements (
-   			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-   			model_id UUID REFERENCES models(id) NOT NULL,
-   			experience VARCHAR(100) NOT NULL,
-   			height INT NOT NULL,
-   			weight INT NOT NULL,
-   			waist INT NOT NULL,
-   			hips INT NOT NULL,
-   			hair_color VARCHAR(100) NOT NULL,
-   			eye_color VARCHAR(100) NOT NULL,
-   			photo TEXT 
-);
-`, `CREATE TABLE IF NOT EXISTS model_documents (
+		// Talent profiles of every category (model, hostess, ...), told apart by talent_type.
+		// Documents and identity checks are shared; each category keeps the attributes
+		// only it records in its own table, keyed by talent_id.
+		`CREATE TABLE IF NOT EXISTS talents (
     		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    		model_id UUID NOT NULL REFERENCES models(id) ON DELETE CASCADE,
+    		talent_type VARCHAR(30) NOT NULL,  -- see talentTypes in handlers/talentTypes.go
+    		user_id INT REFERENCES users(userid) NOT NULL,
+
+    		-- Personal info
+    		first_name VARCHAR(100) NOT NULL,
+    		last_name VARCHAR(100) NOT NULL,
+    		username VARCHAR(50) NOT NULL,
+    		email VARCHAR(255) NOT NULL,
+    		whatsapp VARCHAR(20) NOT NULL,
+    		date_of_birth DATE NOT NULL,
+    		gender VARCHAR(10) NOT NULL CHECK (gender IN ('Female','Male','Other')),
+    		nationality VARCHAR(100) NOT NULL,
+    		street VARCHAR(100) NOT NULL,
+    		city VARCHAR(100) NOT NULL,
+    		residence_country VARCHAR(100) NOT NULL,
+
+    		-- Only asked of some categories
+    		emergency_contact_name VARCHAR(100),
+    		emergency_contact_relationship VARCHAR(50),
+    		emergency_contact_phone VARCHAR(20),
+
+    		-- Status tracking
+    		registration_step INT DEFAULT 1,
+    		status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending','under_review','approved','rejected')),
+    		deleted BOOLEAN DEFAULT FALSE,
+
+    		-- Status emails the talent has chosen to receive
+    		notify_under_review BOOLEAN NOT NULL DEFAULT TRUE,
+    		notify_approved BOOLEAN NOT NULL DEFAULT TRUE,
+    		notify_rejected BOOLEAN NOT NULL DEFAULT TRUE,
+
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    		-- Usernames and emails are unique within a category, the same rule as the
+    		-- UNIQUE username and email of the former models and hostesses tables
+    		UNIQUE (talent_type, username),
+    		UNIQUE (talent_type, email)
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_talents_type_status ON talents(talent_type, status);`,
+		`CREATE INDEX IF NOT EXISTS idx_talents_user_id ON talents(user_id);`,
+		`CREATE TABLE IF NOT EXISTS talent_documents (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		talent_id UUID NOT NULL REFERENCES talents(id) ON DELETE CASCADE,
     		document_issuer_country VARCHAR(100) NOT NULL,
     		document_type VARCHAR(50) NOT NULL CHECK (document_type IN ('National ID Card', 'Passport', 'Driver''s License')),
-    		document_front TEXT NOT NULL,  -- will store the file path or URL
-    		document_back TEXT NOT NULL,   -- same
+    		document_front TEXT NOT NULL,  -- file path
+    		document_back TEXT NOT NULL,   -- file path
     		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-`, `CREATE TABLE IF NOT EXISTS model_identity_check (
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_talent_documents_talent_id ON talent_documents(talent_id);`,
+		`CREATE TABLE IF NOT EXISTS talent_identity_checks (
     		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    		model_id UUID REFERENCES models(id) ON DELETE CASCADE,
-    		selfie_with_id TEXT NOT NULL, -- file path
+    		talent_id UUID NOT NULL REFERENCES talents(id) ON DELETE CASCADE,
+    		selfie_with_id TEXT NOT NULL,  -- file path
     		verified BOOLEAN DEFAULT FALSE,
     		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
-
--- Hostesses Schema here
-
-
-`, `CREATE TABLE IF NOT EXISTS hostesses (
-    	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    	user_id INT REFERENCES users(userid) NOT NULL,
-
-    	-- Personal info
-    	first_name VARCHAR(100) NOT NULL,
-    	last_name VARCHAR(100) NOT NULL,
-    	username VARCHAR(50) UNIQUE NOT NULL,
-    	email VARCHAR(255) UNIQUE NOT NULL,
-    	whatsapp VARCHAR(20) NOT NULL,
-    	date_of_birth DATE NOT NULL,
-    	gender VARCHAR(10) NOT NULL CHECK (gender IN ('Female','Male','Other')),
-    	nationality VARCHAR(100) NOT NULL,
-    	street VARCHAR(100) NOT NULL,
-    	city VARCHAR(100) NOT NULL,
-    	residence_country VARCHAR(100) NOT NULL,
-
-		-- Status tracking
-    	registration_step INT DEFAULT 1,
-    	status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending','under_review','approved','rejected')),
-		deleted BOOLEAN DEFAULT FALSE,
-
-		emergency_contact_name VARCHAR(100),
-    	emergency_contact_relationship VARCHAR(50),
-    	emergency_contact_phone VARCHAR(20),
-
-    	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
-`, `CREATE TABLE IF NOT EXISTS hostess_experience (
-    	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    	hostess_id UUID REFERENCES hostesses(id) ON DELETE CASCADE,
-
-    	work_experience TEXT,
-    	languages TEXT[],        -- e.g. ['English','French','Spanish']
-    	skills TEXT[],            -- e.g. ['Communication','Customer Service']
-    	availability VARCHAR(100),
-    	preferred_events TEXT[],  -- e.g. ['Conferences','Fashion Shows']
-    	previous_hostess_work TEXT,
-		reference_contact TEXT,
-
-		height VARCHAR(10),
-    	weight VARCHAR(10),
-    	hair_color VARCHAR(50),
-    	eye_color VARCHAR(50),
-
-    	-- Media
-    	photo TEXT,
-
-    	-- Social links
-    	social_instagram TEXT,
-    	social_facebook TEXT,
-    	social_twitter TEXT,
-    	social_linkedin TEXT,
-
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-
-);
-`, `CREATE TABLE IF NOT EXISTS hostess_documents (
-    	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    	hostess_id UUID NOT NULL REFERENCES hostesses(id) ON DELETE CASCADE,
-    	document_issuer_country VARCHAR(100) NOT NULL,
-    	document_type VARCHAR(50) NOT NULL CHECK (
-    	    document_type IN ('National ID Card', 'Passport', 'Driver''s License')
-    	),
-    	document_front TEXT NOT NULL,  -- file path or URL
-    	document_back TEXT NOT NULL,   -- file path or URL
-    	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-
-`, `CREATE TABLE IF NOT EXISTS hostess_identity_check (
-    	id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    	hostess_id UUID NOT NULL REFERENCES hostesses(id) ON DELETE CASCADE,
-    	selfie_with_id TEXT NOT NULL,  -- selfie file path
-    	verified BOOLEAN DEFAULT FALSE,
-    	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);
-`,
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_talent_identity_checks_talent_id ON talent_identity_checks(talent_id);`,
+
+		// Model attributes (step 2)
+		`CREATE TABLE IF NOT EXISTS model_measurements (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		talent_id UUID NOT NULL REFERENCES talents(id) ON DELETE CASCADE,
+    		experience VARCHAR(100) NOT NULL,
+    		height INT NOT NULL,
+    		weight INT NOT NULL,
+    		waist INT,
+    		hips INT,
+    		hair_color VARCHAR(100),
+    		eye_color VARCHAR(100),
+    		photo TEXT,
+    		social_instagram TEXT,
+    		social_facebook TEXT,
+    		social_twitter TEXT,
+    		social_linkedin TEXT,
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+
+		// Hostess attributes (step 2)
+		`CREATE TABLE IF NOT EXISTS hostess_experience (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		talent_id UUID NOT NULL REFERENCES talents(id) ON DELETE CASCADE,
+
+    		work_experience TEXT,
+    		languages TEXT[],         -- e.g. ['English','French','Spanish']
+    		skills TEXT[],            -- e.g. ['Communication','Customer Service']
+    		availability VARCHAR(100),
+    		preferred_events TEXT[],  -- e.g. ['Conferences','Fashion Shows']
+    		previous_hostess_work TEXT,
+    		reference_contact TEXT,
+
+    		height VARCHAR(10),
+    		weight VARCHAR(10),
+    		hair_color VARCHAR(50),
+    		eye_color VARCHAR(50),
+
+    		-- Media
+    		photo TEXT,
+
+    		-- Social links
+    		social_instagram TEXT,
+    		social_facebook TEXT,
+    		social_twitter TEXT,
+    		social_linkedin TEXT,
+
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+
+		// Row counts of the copy from the former per-category tables, checked by
+		// FinishLegacyTalentMigration before those tables may be dropped
+		`CREATE TABLE IF NOT EXISTS legacy_talent_copies (
+    		legacy_table TEXT PRIMARY KEY,
+    		legacy_rows INT NOT NULL,
+    		copied_rows INT NOT NULL,
+    		copied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+
+		// One-time copy from the former per-category tables (models, model_documents,
+		// model_identity_check, and the hostess equivalents). Ids are kept, so links
+		// such as inquiry_talents stay valid; the attribute tables get a talent_id next
+		// to their old key. Nothing is deleted: rows that clash on username or email
+		// stay behind in the old tables, which are only dropped by dropLegacyTalentTables.
+		// Deleting a category's rows from legacy_talent_copies runs its copy again.
+		`DO $$
+BEGIN
+    IF to_regclass('models') IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM legacy_talent_copies WHERE legacy_table = 'models') THEN
+        -- Databases created before the status email preferences lack these columns
+        ALTER TABLE models
+            ADD COLUMN IF NOT EXISTS notify_under_review BOOLEAN NOT NULL DEFAULT TRUE,
+            ADD COLUMN IF NOT EXISTS notify_approved BOOLEAN NOT NULL DEFAULT TRUE,
+            ADD COLUMN IF NOT EXISTS notify_rejected BOOLEAN NOT NULL DEFAULT TRUE;
+
+        INSERT INTO talents (id, talent_type, user_id, first_name, last_name, username, email, whatsapp,
+            date_of_birth, gender, nationality, street, city, residence_country,
+            registration_step, status, deleted, notify_under_review, notify_approved, notify_rejected,
+            created_at, updated_at)
+        SELECT id, 'model', user_id, first_name, last_name, username, email, whatsapp,
+            date_of_birth, gender, nationality, street, city, residence_country,
+            registration_step, status, deleted, notify_under_review, notify_approved, notify_rejected,
+            created_at, updated_at
+        FROM models
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO talent_documents (id, talent_id, document_issuer_country, document_type,
+            document_front, document_back, created_at, updated_at)
+        SELECT d.id, d.model_id, d.document_issuer_country, d.document_type,
+            d.document_front, d.document_back, d.created_at, d.updated_at
+        FROM model_documents d
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = d.model_id)
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO talent_identity_checks (id, talent_id, selfie_with_id, verified, created_at)
+        SELECT c.id, c.model_id, c.selfie_with_id, c.verified, c.created_at
+        FROM model_identity_check c
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = c.model_id)
+        ON CONFLICT DO NOTHING;
+
+        ALTER TABLE model_measurements
+            ADD COLUMN IF NOT EXISTS talent_id UUID REFERENCES talents(id) ON DELETE CASCADE,
+            ALTER COLUMN model_id DROP NOT NULL,
+            ALTER COLUMN waist DROP NOT NULL,
+            ALTER COLUMN hips DROP NOT NULL,
+            ALTER COLUMN hair_color DROP NOT NULL,
+            ALTER COLUMN eye_color DROP NOT NULL,
+            ADD COLUMN IF NOT EXISTS social_instagram TEXT,
+            ADD COLUMN IF NOT EXISTS social_facebook TEXT,
+            ADD COLUMN IF NOT EXISTS social_twitter TEXT,
+            ADD COLUMN IF NOT EXISTS social_linkedin TEXT,
+            ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+            ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+        UPDATE model_measurements m SET talent_id = m.model_id
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = m.model_id);
+
+        DELETE FROM legacy_talent_copies WHERE legacy_table LIKE 'model%';
+        INSERT INTO legacy_talent_copies (legacy_table, legacy_rows, copied_rows) VALUES
+            ('models', (SELECT COUNT(*) FROM models),
+                (SELECT COUNT(*) FROM models m JOIN talents t ON t.id = m.id)),
+            ('model_documents', (SELECT COUNT(*) FROM model_documents),
+                (SELECT COUNT(*) FROM model_documents d JOIN talent_documents td ON td.id = d.id)),
+            ('model_identity_check', (SELECT COUNT(*) FROM model_identity_check),
+                (SELECT COUNT(*) FROM model_identity_check c JOIN talent_identity_checks tc ON tc.id = c.id)),
+            ('model_measurements', (SELECT COUNT(*) FROM model_measurements WHERE model_id IS NOT NULL),
+                (SELECT COUNT(*) FROM model_measurements WHERE model_id IS NOT NULL AND talent_id IS NOT NULL));
+    END IF;
+
+    IF to_regclass('hostesses') IS NOT NULL
+        AND NOT EXISTS (SELECT 1 FROM legacy_talent_copies WHERE legacy_table = 'hostesses') THEN
+        -- Databases created before the status email preferences lack these columns
+        ALTER TABLE hostesses
+            ADD COLUMN IF NOT EXISTS notify_under_review BOOLEAN NOT NULL DEFAULT TRUE,
+            ADD COLUMN IF NOT EXISTS notify_approved BOOLEAN NOT NULL DEFAULT TRUE,
+            ADD COLUMN IF NOT EXISTS notify_rejected BOOLEAN NOT NULL DEFAULT TRUE;
+
+        INSERT INTO talents (id, talent_type, user_id, first_name, last_name, username, email, whatsapp,
+            date_of_birth, gender, nationality, street, city, residence_country,
+            emergency_contact_name, emergency_contact_relationship, emergency_contact_phone,
+            registration_step, status, deleted, notify_under_review, notify_approved, notify_rejected,
+            created_at, updated_at)
+        SELECT id, 'hostess', user_id, first_name, last_name, username, email, whatsapp,
+            date_of_birth, gender, nationality, street, city, residence_country,
+            emergency_contact_name, emergency_contact_relationship, emergency_contact_phone,
+            registration_step, status, deleted, notify_under_review, notify_approved, notify_rejected,
+            created_at, updated_at
+        FROM hostesses
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO talent_documents (id, talent_id, document_issuer_country, document_type,
+            document_front, document_back, created_at, updated_at)
+        SELECT d.id, d.hostess_id, d.document_issuer_country, d.document_type,
+            d.document_front, d.document_back, d.created_at, d.updated_at
+        FROM hostess_documents d
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = d.hostess_id)
+        ON CONFLICT DO NOTHING;
+
+        INSERT INTO talent_identity_checks (id, talent_id, selfie_with_id, verified, created_at)
+        SELECT c.id, c.hostess_id, c.selfie_with_id, c.verified, c.created_at
+        FROM hostess_identity_check c
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = c.hostess_id)
+        ON CONFLICT DO NOTHING;
+
+        -- Experience rows without a hostess keep a NULL talent_id
+        ALTER TABLE hostess_experience
+            ADD COLUMN IF NOT EXISTS talent_id UUID REFERENCES talents(id) ON DELETE CASCADE;
+        UPDATE hostess_experience e SET talent_id = e.hostess_id
+        WHERE EXISTS (SELECT 1 FROM talents t WHERE t.id = e.hostess_id);
+
+        DELETE FROM legacy_talent_copies WHERE legacy_table LIKE 'hostess%';
+        INSERT INTO legacy_talent_copies (legacy_table, legacy_rows, copied_rows) VALUES
+            ('hostesses', (SELECT COUNT(*) FROM hostesses),
+                (SELECT COUNT(*) FROM hostesses h JOIN talents t ON t.id = h.id)),
+            ('hostess_documents', (SELECT COUNT(*) FROM hostess_documents),
+                (SELECT COUNT(*) FROM hostess_documents d JOIN talent_documents td ON td.id = d.id)),
+            ('hostess_identity_check', (SELECT COUNT(*) FROM hostess_identity_check),
+                (SELECT COUNT(*) FROM hostess_identity_check c JOIN talent_identity_checks tc ON tc.id = c.id)),
+            ('hostess_experience', (SELECT COUNT(*) FROM hostess_experience WHERE hostess_id IS NOT NULL),
+                (SELECT COUNT(*) FROM hostess_experience WHERE hostess_id IS NOT NULL AND talent_id IS NOT NULL));
+    END IF;
+END $$;`,
+		`CREATE INDEX IF NOT EXISTS idx_model_measurements_talent_id ON model_measurements(talent_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_hostess_experience_talent_id ON hostess_experience(talent_id);`,
 
 		`CREATE TABLE IF NOT EXISTS admins (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -164,5 +279,269 @@ func GetTableQueries() []string {
     updated_at TIMESTAMP DEFAULT NOW(),
     deleted BOOLEAN DEFAULT FALSE
 );`,
+
+		`CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_type VARCHAR(10) NOT NULL CHECK (subject_type IN ('user','admin')),
+    user_id INT REFERENCES users(userid) ON DELETE CASCADE,
+    admin_id UUID REFERENCES admins(id) ON DELETE CASCADE,
+    refresh_token_hash TEXT UNIQUE NOT NULL,   -- sha256 of the current refresh token
+    previous_token_hash TEXT,                  -- last rotated token, used to detect reuse
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_admin_id ON sessions(admin_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_previous_token_hash ON sessions(previous_token_hash);`,
+
+		// Device details so users can tell their sessions apart; ip_address is the last one seen
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';`,
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip_address TEXT NOT NULL DEFAULT '';`,
+
+		// Password reset codes (forgot-password flow)
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_code TEXT;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_expiry TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS reset_code TEXT;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS reset_expiry TIMESTAMP WITH TIME ZONE;`,
+
+		// OTP brute-force protection: codes are stored hashed, with attempt counters and send times
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_attempts INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_attempts INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_sent_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS reset_attempts INT DEFAULT 0;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS reset_sent_at TIMESTAMP WITH TIME ZONE;`,
+
+		// Registration state: pending_verification -> verified_incomplete -> active
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS registration_status VARCHAR(30)
+    		CHECK (registration_status IN ('pending_verification','verified_incomplete','active'));`,
+		`UPDATE users SET registration_status = CASE
+    		WHEN email_verified AND password IS NOT NULL THEN 'active'
+    		WHEN email_verified THEN 'verified_incomplete'
+    		ELSE 'pending_verification'
+    	END
+    	WHERE registration_status IS NULL;`,
+		`ALTER TABLE users ALTER COLUMN registration_status SET DEFAULT 'pending_verification';`,
+		`ALTER TABLE users ALTER COLUMN registration_status SET NOT NULL;`,
+
+		// Account self-service: email change confirmed by a code sent to the new address
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_code TEXT;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_expiry TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_attempts INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS email_change_sent_at TIMESTAMP WITH TIME ZONE;`,
+
+		// Closed accounts are soft-deleted; the original email is kept in closed_email
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted BOOLEAN DEFAULT FALSE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS closed_email VARCHAR(255);`,
+
+		// Login history and lockout after repeated failures
+		`CREATE TABLE IF NOT EXISTS login_attempts (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		subject_type VARCHAR(10) NOT NULL CHECK (subject_type IN ('user','admin')),
+    		identifier TEXT NOT NULL,           -- email or username as typed
+    		ip_address TEXT NOT NULL DEFAULT '',
+    		user_agent TEXT NOT NULL DEFAULT '',
+    		outcome VARCHAR(30) NOT NULL,       -- success, invalid_credentials, unknown_account, locked, inactive
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_login_attempts_created_at ON login_attempts(created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_login_attempts_identifier ON login_attempts(LOWER(identifier));`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_logins INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS last_failed_login_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS lockout_count INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS failed_logins INT DEFAULT 0;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS last_failed_login_at TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS lockout_count INT DEFAULT 0;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE;`,
+
+		// Passwordless sign-in: hash of the single-use magic link token
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS magic_link_hash TEXT;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS magic_link_expiry TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS magic_link_sent_at TIMESTAMP WITH TIME ZONE;`,
+		`CREATE INDEX IF NOT EXISTS idx_users_magic_link_hash ON users(magic_link_hash);`,
+
+		// Phone ownership verified by SMS code; phone numbers are stored in E.164 form
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified BOOLEAN NOT NULL DEFAULT FALSE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verification_code TEXT;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verification_expiry TIMESTAMP WITH TIME ZONE;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verification_attempts INT DEFAULT 0;`,
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verification_sent_at TIMESTAMP WITH TIME ZONE;`,
+
+		// Language of the emails sent to the user (en, fr)
+		`ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_language VARCHAR(5);`,
+
+		// Outgoing mail, written in the same transaction as the change that triggers it
+		// and delivered by a background worker
+		`CREATE TABLE IF NOT EXISTS email_outbox (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		idempotency_key TEXT UNIQUE NOT NULL, -- the same key is only ever queued once
+    		message JSONB NOT NULL,             -- rendered mailer.Message
+    		status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','sending','sent','dead')),
+    		attempts INT NOT NULL DEFAULT 0,
+    		next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    		locked_until TIMESTAMP WITH TIME ZONE, -- claim held by a worker while sending
+    		last_error TEXT,
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    		sent_at TIMESTAMP WITH TIME ZONE
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox(status, next_attempt_at);`,
+		// Messages carrying a code are given up on once the code has expired
+		`ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;`,
+		// Dead messages used to keep their body
+		`UPDATE email_outbox SET message = message - 'Text' - 'HTML' WHERE status = 'dead';`,
+
+		// Contact form submissions, followed up by admins: new -> contacted -> quoted -> won / lost
+		`CREATE TABLE IF NOT EXISTS inquiries (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		name TEXT NOT NULL,
+    		email TEXT NOT NULL,
+    		phone TEXT NOT NULL DEFAULT '',
+    		company TEXT NOT NULL DEFAULT '',
+    		event_type TEXT NOT NULL DEFAULT '',
+    		event_date TEXT NOT NULL DEFAULT '', -- as typed in the form
+    		message TEXT NOT NULL,
+    		status VARCHAR(20) NOT NULL DEFAULT 'new' CHECK (status IN ('new','contacted','quoted','won','lost')),
+    		assigned_to UUID REFERENCES admins(id) ON DELETE SET NULL,
+    		ip_address TEXT NOT NULL DEFAULT '',
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_inquiries_status ON inquiries(status, created_at);`,
+		`CREATE TABLE IF NOT EXISTS inquiry_notes (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		inquiry_id UUID REFERENCES inquiries(id) ON DELETE CASCADE NOT NULL,
+    		admin_id UUID REFERENCES admins(id) ON DELETE SET NULL,
+    		note TEXT NOT NULL,
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_inquiry_notes_inquiry ON inquiry_notes(inquiry_id);`,
+
+		// Contact form submissions refused by the spam checks, kept for review
+		`CREATE TABLE IF NOT EXISTS contact_rejections (
+    		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    		reason VARCHAR(30) NOT NULL,        -- honeypot, too_fast, ip_rate_limit, email_rate_limit, captcha_failed, captcha_error
+    		ip_address TEXT NOT NULL DEFAULT '',
+    		user_agent TEXT NOT NULL DEFAULT '',
+    		name TEXT NOT NULL DEFAULT '',
+    		email TEXT NOT NULL DEFAULT '',
+    		message TEXT NOT NULL DEFAULT '',
+    		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_contact_rejections_created_at ON contact_rejections(created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_inquiries_ip_address ON inquiries(ip_address, created_at);`,
+		`CREATE INDEX IF NOT EXISTS idx_inquiries_email ON inquiries(LOWER(email), created_at);`,
+
+		// Reference quoted to the sender of a contact form, e.g. INQ-2026-000042
+		`CREATE SEQUENCE IF NOT EXISTS inquiry_reference_seq;`,
+		`ALTER TABLE inquiries ADD COLUMN IF NOT EXISTS reference TEXT UNIQUE NOT NULL
+    		DEFAULT 'INQ-' || TO_CHAR(NOW(), 'YYYY') || '-' || LPAD(nextval('inquiry_reference_seq')::TEXT, 6, '0');`,
+		`ALTER TABLE inquiries ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP WITH TIME ZONE; -- auto-reply queued`,
+
+		// Talents a contact form enquiry is about; username is kept as it was when the client asked
+		`CREATE TABLE IF NOT EXISTS inquiry_talents (
+    		inquiry_id UUID REFERENCES inquiries(id) ON DELETE CASCADE NOT NULL,
+    		talent_type VARCHAR(30) NOT NULL,  -- as in talents.talent_type
+    		talent_id UUID NOT NULL,
+    		username TEXT NOT NULL,
+    		PRIMARY KEY (inquiry_id, talent_type, talent_id)
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_inquiry_talents_talent ON inquiry_talents(talent_type, talent_id);`,
+		// Any talent category can be enquired about, not only models and hostesses
+		`ALTER TABLE inquiry_talents DROP CONSTRAINT IF EXISTS inquiry_talents_talent_type_check;`,
+		`ALTER TABLE inquiry_talents ALTER COLUMN talent_type TYPE VARCHAR(30);`,
+
+		// Admin two-factor authentication (TOTP)
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_secret TEXT;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN DEFAULT FALSE;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_last_step BIGINT DEFAULT 0; -- last accepted time step, blocks replays`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_failed_attempts INT DEFAULT 0;`,
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS totp_locked_until TIMESTAMP WITH TIME ZONE;`,
+		// Challenge tokens of the 2FA login step; a token works until its row is used
+		`CREATE TABLE IF NOT EXISTS admin_login_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE TABLE IF NOT EXISTS admin_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    admin_id UUID NOT NULL REFERENCES admins(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+		`CREATE INDEX IF NOT EXISTS idx_admin_recovery_codes_admin_id ON admin_recovery_codes(admin_id);`,
+
+		// Admin roles: accounts with the old catch-all 'admin' role, which the open
+		// /api/admin/register handed out, become read-only viewers. The first super admin
+		// comes from BOOTSTRAP_ADMIN_* (see handlers.BootstrapSuperAdmin).
+		`UPDATE admins SET role = 'viewer' WHERE role = 'admin' OR role IS NULL;`,
+		`ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'viewer';`,
+		`ALTER TABLE admins DROP CONSTRAINT IF EXISTS admins_role_check;`,
+		`ALTER TABLE admins ADD CONSTRAINT admins_role_check
+    CHECK (role IN ('super_admin','reviewer','editor','viewer'));`,
+
+		`CREATE TABLE IF NOT EXISTS admin_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL CHECK (role IN ('super_admin','reviewer','editor','viewer')),
+    token_hash TEXT UNIQUE NOT NULL,   -- sha256 of the emailed one-time token
+    invited_by UUID REFERENCES admins(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    accepted_admin_id UUID REFERENCES admins(id),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
+
+		`ALTER TABLE admins ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN DEFAULT FALSE;`,
+
+		`CREATE TABLE IF NOT EXISTS settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`,
 	}
 }
+
+// dropLegacyTalentTables is the second half of the move to talents: it removes the
+// former per-category tables, and fails without dropping anything while one of their
+// rows is still missing from the talents tables.
+const dropLegacyTalentTables = `DO $$
+DECLARE
+    missing INT;
+BEGIN
+    IF to_regclass('models') IS NOT NULL THEN
+        SELECT (SELECT COUNT(*) FROM models m WHERE NOT EXISTS (SELECT 1 FROM talents t WHERE t.id = m.id))
+            + (SELECT COUNT(*) FROM model_documents d WHERE NOT EXISTS (SELECT 1 FROM talent_documents td WHERE td.id = d.id))
+            + (SELECT COUNT(*) FROM model_identity_check c
+                WHERE c.model_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM talent_identity_checks tc WHERE tc.id = c.id))
+        INTO missing;
+        IF missing > 0 THEN
+            RAISE EXCEPTION '% legacy model rows are not in the talents tables yet', missing;
+        END IF;
+
+        ALTER TABLE model_measurements DROP COLUMN model_id, ALTER COLUMN talent_id SET NOT NULL;
+        DROP TABLE model_documents, model_identity_check, models;
+    END IF;
+
+    IF to_regclass('hostesses') IS NOT NULL THEN
+        SELECT (SELECT COUNT(*) FROM hostesses h WHERE NOT EXISTS (SELECT 1 FROM talents t WHERE t.id = h.id))
+            + (SELECT COUNT(*) FROM hostess_documents d WHERE NOT EXISTS (SELECT 1 FROM talent_documents td WHERE td.id = d.id))
+            + (SELECT COUNT(*) FROM hostess_identity_check c WHERE NOT EXISTS (SELECT 1 FROM talent_identity_checks tc WHERE tc.id = c.id))
+        INTO missing;
+        IF missing > 0 THEN
+            RAISE EXCEPTION '% legacy hostess rows are not in the talents tables yet', missing;
+        END IF;
+
+        ALTER TABLE hostess_experience DROP COLUMN hostess_id;
+        DROP TABLE hostess_documents, hostess_identity_check, hostesses;
+    END IF;
+END $$;`
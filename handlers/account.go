@@ -1,13 +1,43 @@
 package handlers
 
 import (
+	"database/sql"
+	"fmt"
+	"models/auth"
 	"models/database"
+	"models/mailer"
+	"models/outbox"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
+
+type UpdateAccountRequest struct {
+	Fullname    string `json:"fullname"`
+	Username    string `json:"username"`
+	PhoneNumber string `json:"phone_number"`
+	Position    string `json:"position"`
+	Language    string `json:"language"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required"`
+}
+
+type CloseAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 func GetAccountInfo(c *gin.Context) {
-	userID := int(c.MustGet("user_id").(float64))
+	userID := c.MustGet("user_id").(int)
 
 	var user struct {
 		ID       int    `json:"id"`
@@ -15,10 +45,11 @@ func GetAccountInfo(c *gin.Context) {
 		Username string `json:"username"`
 		Fullname string `json:"fullname"`
 		Phone    string `json:"phone_number"`
-
+		PhoneVerified bool `json:"phone_verified"`
+		Language string `json:"language"`
 	}
-	err := database.DB.QueryRow(`SELECT userid, email, username, fullname, phone_number FROM users WHERE userid = $1`, userID).
-		Scan(&user.ID, &user.Email, &user.Username, &user.Fullname, &user.Phone)
+	err := database.DB.QueryRow(`SELECT userid, email, username, fullname, phone_number, phone_verified, COALESCE(preferred_language, $2) FROM users WHERE userid = $1`, userID, mailer.DefaultLanguage()).
+		Scan(&user.ID, &user.Email, &user.Username, &user.Fullname, &user.Phone, &user.PhoneVerified, &user.Language)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
@@ -27,3 +58,359 @@ func GetAccountInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
+
+// PUT /api/account
+// Empty fields are left unchanged.
+func UpdateAccount(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	if !normalizePhoneField(c, "phone_number", &req.PhoneNumber, false) {
+		return
+	}
+	req.Language = strings.ToLower(strings.TrimSpace(req.Language))
+	if req.Language != "" && !mailer.IsSupportedLanguage(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language", "code": "invalid_language"})
+		return
+	}
+
+	// A new phone number has to be verified again
+	_, err := database.DB.Exec(`
+		UPDATE users SET
+			phone_verified = CASE WHEN $4 <> '' AND $4 IS DISTINCT FROM phone_number THEN FALSE ELSE phone_verified END,
+			fullname = COALESCE(NULLIF($2, ''), fullname),
+			username = COALESCE(NULLIF($3, ''), username),
+			phone_number = COALESCE(NULLIF($4, ''), phone_number),
+			position = COALESCE(NULLIF($5, ''), position),
+			preferred_language = COALESCE(NULLIF($6, ''), preferred_language)
+		WHERE userid = $1
+	`, userID, req.Fullname, req.Username, req.PhoneNumber, req.Position, req.Language)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already in use"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+		fmt.Println("Update account error:", err)
+		return
+	}
+
+	GetAccountInfo(c)
+}
+
+// PUT /api/account/password
+// Needs the current password; every other session is signed out.
+func ChangePassword(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Current and new password are required"})
+		return
+	}
+
+	var passwordHash, email, username string
+	err := database.DB.QueryRow(`
+		SELECT password, email, COALESCE(username, '') FROM users WHERE userid = $1
+	`, userID).Scan(&passwordHash, &email, &username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ok, _, _ := auth.VerifyPassword(passwordHash, req.CurrentPassword); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if rejectWeakPassword(c, req.NewPassword, email, username) {
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	_, err = database.DB.Exec(`UPDATE users SET password = $1 WHERE userid = $2`, hashedPassword, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`, userID, c.GetString("session_id"))
+	if err != nil {
+		fmt.Println("Failed to revoke sessions:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// emailInUse checks users and talents, since both keep the email unique
+func emailInUse(email string, userID int) (bool, error) {
+	var taken bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND userid <> $2)
+		    OR EXISTS(SELECT 1 FROM talents WHERE LOWER(email) = $1 AND user_id <> $2)
+	`, email, userID).Scan(&taken)
+	return taken, err
+}
+
+func sendEmailChangeEmail(q outbox.Execer, email, lang, otp string) error {
+	return queueCodeEmail(q, "email_change:"+hashOTP(email, otp), email, lang, "email_change", map[string]interface{}{
+		"Code":    otp,
+		"Minutes": int(otpTTL.Minutes()),
+	}, time.Now().Add(otpTTL))
+}
+
+// POST /api/account/email
+// Sends a code to the new address; the email only changes once it is confirmed.
+func RequestEmailChange(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email is required"})
+		return
+	}
+	req.NewEmail = strings.ToLower(strings.TrimSpace(req.NewEmail))
+
+	if !isValidEmail(req.NewEmail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	taken, err := emailInUse(req.NewEmail, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
+
+	var sentAt sql.NullTime
+	err = database.DB.QueryRow(`SELECT email_change_sent_at FROM users WHERE userid = $1`, userID).Scan(&sentAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if wait := resendWait(sentAt); wait > 0 {
+		respondTooManyRequests(c, "resend_cooldown", "Please wait before requesting a new code", wait)
+		return
+	}
+
+	otp, err := generateOTP()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		return
+	}
+
+	lang := userLanguage(c, c.GetString("email"))
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET pending_email = $1, email_change_code = $2, email_change_expiry = $3,
+		    email_change_attempts = 0, email_change_sent_at = NOW()
+		WHERE userid = $4
+	`, req.NewEmail, hashOTP(req.NewEmail, otp), time.Now().Add(otpTTL), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OTP"})
+		return
+	}
+
+	if err := sendEmailChangeEmail(tx, req.NewEmail, lang, otp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send OTP"})
+		fmt.Println("Email change email error:", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OTP"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent to the new email"})
+}
+
+// POST /api/account/email/verify
+// Confirms the code and moves the user and their model and hostess profiles to the new email.
+func ConfirmEmailChange(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	var (
+		pendingEmail, dbCode sql.NullString
+		expiry, sentAt       sql.NullTime
+		attempts             int
+	)
+	err := database.DB.QueryRow(`
+		SELECT pending_email, email_change_sent_at FROM users WHERE userid = $1
+	`, userID).Scan(&pendingEmail, &sentAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !pendingEmail.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No email change pending"})
+		return
+	}
+
+	// Count the guess before comparing, so parallel requests cannot all slip
+	// under the limit. No row means the limit was already reached.
+	err = database.DB.QueryRow(`
+		UPDATE users SET email_change_attempts = email_change_attempts + 1
+		WHERE userid = $1 AND pending_email IS NOT NULL AND email_change_attempts < $2
+		RETURNING pending_email, email_change_code, email_change_expiry, email_change_attempts
+	`, userID, maxOTPAttempts).Scan(&pendingEmail, &dbCode, &expiry, &attempts)
+	if err == sql.ErrNoRows {
+		respondTooManyRequests(c, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !expiry.Valid || time.Now().After(expiry.Time) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired"})
+		return
+	}
+
+	if !otpMatches(dbCode, pendingEmail.String, req.Code) {
+		if attempts >= maxOTPAttempts {
+			// Burn the code once the limit is reached
+			database.DB.Exec(`UPDATE users SET email_change_code = NULL WHERE userid = $1 AND email_change_attempts >= $2`, userID, maxOTPAttempts)
+			respondTooManyRequests(c, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code", "attempts_remaining": maxOTPAttempts - attempts})
+		return
+	}
+
+	newEmail := pendingEmail.String
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Matching on the code makes sure it is only used once
+	res, err := tx.Exec(`
+		UPDATE users SET email = $1, pending_email = NULL, email_change_code = NULL,
+		    email_change_expiry = NULL, email_change_attempts = 0
+		WHERE userid = $2 AND email_change_code = $3
+	`, newEmail, userID, dbCode.String)
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired"})
+			return
+		}
+		_, err = tx.Exec(`UPDATE talents SET email = $1, updated_at = NOW() WHERE user_id = $2`, newEmail, userID)
+	}
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change email"})
+		fmt.Println("Change email error:", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	// The current access token still carries the old email, hand out a fresh one
+	token, err := signUserToken(userID, newEmail, c.GetString("session_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Email changed successfully",
+		"email":      newEmail,
+		"token":      token,
+		"expires_in": int(accessTokenTTL.Seconds()),
+	})
+}
+
+// DELETE /api/account
+// Soft-deletes the user and their model and hostess profiles, which also takes
+// them out of the public galleries. The email is released so it can register again.
+func CloseAccount(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req CloseAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password is required to close the account"})
+		return
+	}
+
+	var passwordHash string
+	err := database.DB.QueryRow(`SELECT password FROM users WHERE userid = $1`, userID).Scan(&passwordHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ok, _, _ := auth.VerifyPassword(passwordHash, req.Password); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Unique emails are replaced by a placeholder; the original stays in closed_email
+	queries := []string{
+		`UPDATE users SET deleted = TRUE, deleted_at = NOW(), closed_email = email,
+		     email = 'closed-' || userid || '-' || EXTRACT(EPOCH FROM NOW())::BIGINT || '@deleted.invalid',
+		     phone_number = NULL
+		 WHERE userid = $1`,
+		`UPDATE talents SET deleted = TRUE, updated_at = NOW(),
+		     email = 'closed-' || id || '@deleted.invalid'
+		 WHERE user_id = $1`,
+		`UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`,
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close account"})
+			fmt.Println("Close account error:", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account closed"})
+}
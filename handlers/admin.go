@@ -12,6 +12,8 @@ type Admin struct {
     FullName     string    `json:"full_name"`
     Role         string    `json:"role"`
     IsActive     bool      `json:"is_active"`
+    TOTPEnabled  bool      `json:"totp_enabled"`
+    PasswordResetRequired bool `json:"password_reset_required"`
     LastLogin    time.Time `json:"last_login,omitempty"`
     CreatedAt    time.Time `json:"created_at"`
     UpdatedAt    time.Time `json:"updated_at"`
@@ -23,9 +25,15 @@ type AdminLoginRequest struct {
     Password string `json:"password" binding:"required"`
 }
 
-type AdminRegisterRequest struct {
+type AdminInvitationRequest struct {
+    Email          string `json:"email" binding:"required,email"`
+    Role           string `json:"role" binding:"required"`
+    ExpiresInHours int    `json:"expires_in_hours"`
+}
+
+type AcceptInvitationRequest struct {
+    Token    string `json:"token" binding:"required"`
     Username string `json:"username" binding:"required"`
-    Email    string `json:"email" binding:"required,email"`
-    Password string `json:"password" binding:"required,min=6"`
+    Password string `json:"password" binding:"required"`
     FullName string `json:"full_name" binding:"required"`
 }
\ No newline at end of file
@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/auth"
+	"models/database"
+	"models/mailer"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type AdminProfileUpdateRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+type AdminChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// removesLastSuperAdmin reports whether taking the target out of the active
+// super admins would leave none. The super admin rows are locked until tx ends.
+func removesLastSuperAdmin(tx *sql.Tx, targetID string) (bool, error) {
+	rows, err := tx.Query(`
+		SELECT id FROM admins
+		WHERE role = $1 AND is_active = TRUE AND deleted = FALSE
+		FOR UPDATE
+	`, RoleSuperAdmin)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	targetIsSuperAdmin, others := false, 0
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return false, err
+		}
+		if id == targetID {
+			targetIsSuperAdmin = true
+		} else {
+			others++
+		}
+	}
+	return targetIsSuperAdmin && others == 0, rows.Err()
+}
+
+// beginAdminAccountChange starts the transaction of a change to another admin, refusing
+// self-targeting and anything that would leave no active super admin. On success the
+// caller owns tx; on failure the response is already written.
+func beginAdminAccountChange(c *gin.Context, action string) (*sql.Tx, bool) {
+	targetID := c.Param("id")
+	if targetID == c.GetString("admin_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("You cannot %s your own account", action)})
+		return nil, false
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return nil, false
+	}
+
+	last, err := removesLastSuperAdmin(tx, targetID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	if last {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This is the last active super admin",
+			"code":  "last_super_admin",
+		})
+		return nil, false
+	}
+	return tx, true
+}
+
+// updateAdminAccount runs an UPDATE on another admin, with the checks of beginAdminAccountChange
+func updateAdminAccount(c *gin.Context, action, query string, args ...interface{}) bool {
+	tx, ok := beginAdminAccountChange(c, action)
+	if !ok {
+		return false
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(query, append([]interface{}{c.Param("id")}, args...)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to %s admin", action)})
+		fmt.Println("Admin update error:", err)
+		return false
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return false
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return false
+	}
+	return true
+}
+
+// GET /api/admin/admins
+// Optional filters: ?role=reviewer, ?active=true|false
+func AdminListAdmins(c *gin.Context) {
+	query := `
+		SELECT id, username, email, COALESCE(full_name, ''), role, is_active, totp_enabled, last_login, created_at
+		FROM admins
+		WHERE deleted = FALSE
+	`
+	var args []interface{}
+	if role := c.Query("role"); role != "" {
+		args = append(args, role)
+		query += fmt.Sprintf(" AND role = $%d", len(args))
+	}
+	if active := c.Query("active"); active != "" {
+		args = append(args, active == "true")
+		query += fmt.Sprintf(" AND is_active = $%d", len(args))
+	}
+	query += " ORDER BY created_at ASC"
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch admins"})
+		return
+	}
+	defer rows.Close()
+
+	admins := []gin.H{}
+	for rows.Next() {
+		var a Admin
+		var lastLogin sql.NullTime
+		if err := rows.Scan(&a.ID, &a.Username, &a.Email, &a.FullName, &a.Role,
+			&a.IsActive, &a.TOTPEnabled, &lastLogin, &a.CreatedAt); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+
+		admin := gin.H{
+			"id":           a.ID,
+			"username":     a.Username,
+			"email":        a.Email,
+			"full_name":    a.FullName,
+			"role":         a.Role,
+			"is_active":    a.IsActive,
+			"totp_enabled": a.TOTPEnabled,
+			"last_login":   nil,
+			"created_at":   a.CreatedAt,
+		}
+		if lastLogin.Valid {
+			admin["last_login"] = lastLogin.Time
+		}
+		admins = append(admins, admin)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"admins": admins, "count": len(admins)})
+}
+
+// The :id handlers below compare id::TEXT, as the sessions handlers do, so that an id
+// that is not a UUID is "Admin not found" rather than a database error.
+
+// PUT /api/admin/admins/:id/role
+func AdminChangeRole(c *gin.Context) {
+	var req AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role is required"})
+		return
+	}
+	if !isValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown role: %s", req.Role)})
+		return
+	}
+
+	// Promoting to super admin never removes one, so skip the guard's count in that case
+	query := `UPDATE admins SET role = $2, updated_at = NOW() WHERE id::TEXT = $1 AND deleted = FALSE`
+	if req.Role == RoleSuperAdmin {
+		res, err := database.DB.Exec(query, c.Param("id"), req.Role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+			return
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+			return
+		}
+	} else if !updateAdminAccount(c, "change the role of", query, req.Role) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role updated", "role": req.Role})
+}
+
+// POST /api/admin/admins/:id/deactivate
+func AdminDeactivateAdmin(c *gin.Context) {
+	if !updateAdminAccount(c, "deactivate",
+		`UPDATE admins SET is_active = FALSE, updated_at = NOW() WHERE id::TEXT = $1 AND deleted = FALSE`) {
+		return
+	}
+
+	if err := revokeAdminSessions(c.Param("id")); err != nil {
+		fmt.Println("Failed to revoke admin sessions:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin deactivated"})
+}
+
+// POST /api/admin/admins/:id/reactivate
+func AdminReactivateAdmin(c *gin.Context) {
+	res, err := database.DB.Exec(`
+		UPDATE admins SET is_active = TRUE, updated_at = NOW() WHERE id::TEXT = $1 AND deleted = FALSE
+	`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reactivate admin"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin reactivated"})
+}
+
+// DELETE /api/admin/admins/:id
+func AdminDeleteAdmin(c *gin.Context) {
+	if !updateAdminAccount(c, "delete",
+		`UPDATE admins SET deleted = TRUE, is_active = FALSE, updated_at = NOW() WHERE id::TEXT = $1 AND deleted = FALSE`) {
+		return
+	}
+
+	if err := revokeAdminSessions(c.Param("id")); err != nil {
+		fmt.Println("Failed to revoke admin sessions:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Admin deleted successfully"})
+}
+
+// POST /api/admin/admins/:id/force-password-reset
+// Signs the admin out everywhere and blocks password login until they pick a new
+// password with the emailed reset code. Like deactivation, it cannot target the
+// caller or the last active super admin, who would otherwise be locked out.
+func AdminForcePasswordReset(c *gin.Context) {
+	targetID := c.Param("id")
+
+	otp, err := generateOTP()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		return
+	}
+
+	tx, ok := beginAdminAccountChange(c, "force a password reset on")
+	if !ok {
+		return
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRow(`SELECT email FROM admins WHERE id::TEXT = $1 AND deleted = FALSE FOR UPDATE`, targetID).Scan(&email)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	email = strings.ToLower(email)
+
+	_, err = tx.Exec(`
+		UPDATE admins
+		SET password_reset_required = TRUE, reset_code = $1, reset_expiry = $2,
+		    reset_attempts = 0, reset_sent_at = NOW(), updated_at = NOW()
+		WHERE id::TEXT = $3
+	`, hashOTP(email, otp), time.Now().Add(otpTTL), targetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		return
+	}
+
+	if err := sendPasswordResetEmail(tx, email, mailer.DefaultLanguage(), otp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		fmt.Println("Failed to queue admin password reset email:", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to force password reset"})
+		return
+	}
+
+	if err := revokeAdminSessions(targetID); err != nil {
+		fmt.Println("Failed to revoke admin sessions:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset required, a reset code was sent to the admin"})
+}
+
+// PUT /api/admin/profile
+func AdminUpdateProfile(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req AdminProfileUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if req.Email != "" && !isValidEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email format"})
+		return
+	}
+
+	var taken bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE id <> $1 AND
+		    ((NULLIF($2, '') IS NOT NULL AND username = $2) OR (NULLIF($3, '') IS NOT NULL AND LOWER(email) = $3)))
+	`, adminID, req.Username, req.Email).Scan(&taken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Admin with this username or email already exists"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE admins SET
+		    username = COALESCE(NULLIF($2, ''), username),
+		    email = COALESCE(NULLIF($3, ''), email),
+		    full_name = COALESCE(NULLIF($4, ''), full_name),
+		    updated_at = NOW()
+		WHERE id = $1
+	`, adminID, req.Username, req.Email, req.FullName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	GetAdminProfile(c)
+}
+
+// PUT /api/admin/password
+// Changes the signed-in admin's password and ends their other sessions.
+func AdminChangePassword(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req AdminChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var passwordHash, username, email string
+	err := database.DB.QueryRow(`
+		SELECT password_hash, username, email FROM admins WHERE id = $1
+	`, adminID).Scan(&passwordHash, &username, &email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ok, _, _ := auth.VerifyPassword(passwordHash, req.CurrentPassword); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if rejectWeakPassword(c, req.NewPassword, email, username) {
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE admins SET password_hash = $1, password_reset_required = FALSE, updated_at = NOW() WHERE id = $2
+	`, hashedPassword, adminID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE admin_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`, adminID, c.GetString("session_id"))
+	if err != nil {
+		fmt.Println("Failed to revoke admin sessions:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
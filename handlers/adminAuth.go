@@ -3,74 +3,15 @@ package handlers
 import (
 	"database/sql"
 	"fmt"
+	"models/auth"
 	"models/database"
 
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
 )
 
-
-
-var adminJWTSecret = []byte(os.Getenv("ADMIN_JWT_SECRET"))
-
-func init() {
-    if len(adminJWTSecret) == 0 {
-        adminJWTSecret = []byte("admin-secret-key-change-in-production")
-    }
-}
-
-// AdminRegister handles admin registration
-func AdminRegister(c *gin.Context) {
-    var req AdminRegisterRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-    // Check if admin already exists
-    var existingAdmin Admin
-    err := database.DB.QueryRow(`
-        SELECT id FROM admins WHERE (username = $1 OR email = $2) AND deleted = FALSE
-    `, req.Username, req.Email).Scan(&existingAdmin.ID)
-
-    if err == nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Admin with this username or email already exists"})
-        return
-    } else if err != sql.ErrNoRows {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
-        return
-    }
-
-    // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-        return
-    }
-
-    // Create admin
-    var adminID string
-    err = database.DB.QueryRow(`
-        INSERT INTO admins (username, email, password_hash, full_name, role)
-        VALUES ($1, $2, $3, $4, 'admin')
-        RETURNING id
-    `, req.Username, req.Email, string(hashedPassword), req.FullName).Scan(&adminID)
-
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
-        return
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "message": "Admin created successfully",
-        "admin_id": adminID,
-    })
-}
-
 // AdminLogin handles admin authentication
 func AdminLogin(c *gin.Context) {
     var req AdminLoginRequest
@@ -82,16 +23,17 @@ func AdminLogin(c *gin.Context) {
     // Find admin
     var admin Admin
     err := database.DB.QueryRow(`
-        SELECT id, username, email, password_hash, full_name, role, is_active
+        SELECT id, username, email, password_hash, full_name, role, is_active, totp_enabled, password_reset_required
         FROM admins 
         WHERE username = $1 AND deleted = FALSE
     `, req.Username).Scan(
         &admin.ID, &admin.Username, &admin.Email, &admin.PasswordHash,
-        &admin.FullName, &admin.Role, &admin.IsActive,
+        &admin.FullName, &admin.Role, &admin.IsActive, &admin.TOTPEnabled, &admin.PasswordResetRequired,
     )
 
     if err != nil {
         if err == sql.ErrNoRows {
+            recordLoginAttempt(c, adminAccounts.subjectType, req.Username, LoginUnknownAccount)
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
         } else {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
@@ -101,44 +43,113 @@ func AdminLogin(c *gin.Context) {
 
     // Check if admin is active
     if !admin.IsActive {
+        recordLoginAttempt(c, adminAccounts.subjectType, req.Username, LoginInactive)
         c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin account is deactivated"})
         return
     }
 
+    // Refuse while the account is locked after repeated failures
+    if adminAccounts.respondIfLocked(c, admin.ID, req.Username) {
+        return
+    }
+
     // Verify password
-    if err := bcrypt.CompareHashAndPassword([]byte(admin.PasswordHash), []byte(req.Password)); err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+    ok, needsRehash, err := auth.VerifyPassword(admin.PasswordHash, req.Password)
+    if err != nil {
+        fmt.Println("Password hash error:", err)
+    }
+    if !ok {
+        adminAccounts.handleFailedLogin(c, admin.ID, req.Username, admin.Email, "Invalid credentials")
+        return
+    }
+
+    // Upgrade bcrypt or weaker argon2id hashes now that the plain password is known
+    if needsRehash {
+        if hashed, err := auth.HashPassword(req.Password); err == nil {
+            database.DB.Exec(`UPDATE admins SET password_hash = $1 WHERE id = $2`, hashed, admin.ID)
+        }
+    }
+
+    if _, err := adminAccounts.clearFailures(admin.ID); err != nil {
+        fmt.Println("Failed to reset login failures:", err)
+    }
+    recordLoginAttempt(c, adminAccounts.subjectType, req.Username, LoginSuccess)
+
+    // A super admin forced a reset; the old password no longer opens a session
+    if admin.PasswordResetRequired {
+        c.JSON(http.StatusForbidden, gin.H{
+            "error": "Password reset required, use the code sent to your email",
+            "code":  "password_reset_required",
+        })
         return
     }
 
+    // Second step: the password alone only buys a short-lived challenge token.
+    // Admins without 2FA get an enrolment challenge when 2FA is mandatory.
+    if admin.TOTPEnabled || getBoolSetting(SettingRequireAdmin2FA) {
+        purpose := challengeVerify2FA
+        if !admin.TOTPEnabled {
+            purpose = challengeEnroll2FA
+        }
+
+        challenge, err := signAdminChallenge(admin.ID, purpose)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+            return
+        }
+
+        c.JSON(http.StatusOK, gin.H{
+            "message":             "Two-factor authentication required",
+            "two_factor_required": true,
+            "setup_required":      purpose == challengeEnroll2FA,
+            "challenge_token":     challenge,
+            "expires_in":          int(adminChallengeTTL.Seconds()),
+        })
+        return
+    }
+
+    respondAdminLogin(c, admin, nil)
+}
+
+// respondAdminLogin opens a session for a fully authenticated admin and writes the login response.
+// extra is merged into the response body.
+func respondAdminLogin(c *gin.Context, admin Admin, extra gin.H) {
     // Update last login
     database.DB.Exec(`UPDATE admins SET last_login = NOW() WHERE id = $1`, admin.ID)
 
-    // Generate JWT token
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-        "admin_id": admin.ID,
-        "username": admin.Username,
-        "role":     admin.Role,
-        "exp":      time.Now().Add(24 * time.Hour).Unix(),
-    })
+    // Open a session and generate a short-lived JWT bound to it
+    sessionID, refreshToken, err := createAdminSession(c, admin.ID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+        fmt.Println("Session error:", err)
+        return
+    }
 
-    tokenString, err := token.SignedString(adminJWTSecret)
+    tokenString, err := signAdminToken(admin.ID, admin.Username, admin.Role, sessionID)
     if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Login successful",
-        "token":   tokenString,
+    response := gin.H{
+        "message":       "Login successful",
+        "token":         tokenString,
+        "refresh_token": refreshToken,
+        "expires_in":    int(accessTokenTTL.Seconds()),
         "admin": gin.H{
             "id":       admin.ID,
             "username": admin.Username,
             "email":    admin.Email,
             "full_name": admin.FullName,
             "role":     admin.Role,
+            "permissions": permissionsFor(admin.Role),
         },
-    })
+    }
+    for k, v := range extra {
+        response[k] = v
+    }
+
+    c.JSON(http.StatusOK, response)
 }
 
 // AdminAuthMiddleware for protecting admin routes
@@ -151,37 +162,54 @@ func AdminAuthMiddleware() gin.HandlerFunc {
             return
         }
 
-        if len(authHeader) < 7 || authHeader[:7] != "Bearer " {
+        tokenString, ok := auth.BearerToken(authHeader)
+        if !ok {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
             c.Abort()
             return
         }
 
-        tokenString := authHeader[7:]
-        token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-            if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-                return nil, fmt.Errorf("unexpected signing method: %v", token.Method)
-            }
-            return adminJWTSecret, nil
-        })
+        claims, err := auth.Verify(tokenString, auth.AudienceAdmin)
+        if err != nil {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+            c.Abort()
+            return
+        }
 
-        if err != nil || !token.Valid {
+        // The session must still be open and the admin still active
+        sessionID := claims.SessionID
+        adminID := claims.Subject
+        if sessionID == "" {
             c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
             c.Abort()
             return
         }
 
-        claims, ok := token.Claims.(jwt.MapClaims)
-        if !ok {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+        var role string
+        err = database.DB.QueryRow(`
+            SELECT a.role
+            FROM sessions s
+            JOIN admins a ON a.id = s.admin_id
+            WHERE s.id = $1 AND s.admin_id = $2
+              AND s.revoked_at IS NULL AND s.expires_at > NOW()
+              AND a.is_active = TRUE AND a.deleted = FALSE
+        `, sessionID, adminID).Scan(&role)
+
+        if err == sql.ErrNoRows {
+            c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+            c.Abort()
+            return
+        } else if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
             c.Abort()
             return
         }
 
         // Set admin info in context
-        c.Set("admin_id", claims["admin_id"])
-        c.Set("username", claims["username"])
-        c.Set("role", claims["role"])
+        c.Set("admin_id", adminID)
+        c.Set("username", claims.Username)
+        c.Set("role", role)
+        c.Set("session_id", sessionID)
 
         c.Next()
     }
@@ -207,6 +235,74 @@ func GetAdminProfile(c *gin.Context) {
     }
 
     c.JSON(http.StatusOK, gin.H{
-        "admin": admin,
+        "admin":       admin,
+        "permissions": permissionsFor(admin.Role),
     })
-}
\ No newline at end of file
+}
+
+// Purposes of the challenge token handed out between password and 2FA steps
+const (
+    challengeVerify2FA = "2fa_verify"
+    challengeEnroll2FA = "2fa_enroll"
+)
+
+const adminChallengeTTL = 5 * time.Minute
+
+// adminChallenge is a valid, not yet used challenge token
+type adminChallenge struct {
+    id, adminID, purpose string
+}
+
+// signAdminChallenge issues the token that proves the password step passed.
+// Its audience is not the admin one, so AdminAuthMiddleware never accepts it,
+// and it refers to a challenge row so that it can only complete one login.
+func signAdminChallenge(adminID, purpose string) (string, error) {
+    // Challenges live for minutes, older rows are of no use
+    database.DB.Exec(`DELETE FROM admin_login_challenges WHERE expires_at < NOW() - INTERVAL '1 day'`)
+
+    var challengeID string
+    err := database.DB.QueryRow(`
+        INSERT INTO admin_login_challenges (admin_id, purpose, expires_at) VALUES ($1, $2, $3)
+        RETURNING id
+    `, adminID, purpose, time.Now().Add(adminChallengeTTL)).Scan(&challengeID)
+    if err != nil {
+        return "", err
+    }
+    return auth.Issue(adminID, auth.AudienceAdminChallenge, adminChallengeTTL, auth.Claims{Purpose: purpose, Challenge: challengeID})
+}
+
+// parseAdminChallenge validates a challenge token and checks that it was not used yet
+func parseAdminChallenge(tokenString string) (*adminChallenge, error) {
+    claims, err := auth.Verify(tokenString, auth.AudienceAdminChallenge)
+    if err != nil {
+        return nil, fmt.Errorf("invalid challenge token")
+    }
+    if claims.Purpose != challengeVerify2FA && claims.Purpose != challengeEnroll2FA {
+        return nil, fmt.Errorf("invalid challenge token")
+    }
+
+    var open bool
+    err = database.DB.QueryRow(`
+        SELECT EXISTS(SELECT 1 FROM admin_login_challenges
+            WHERE id::TEXT = $1 AND admin_id::TEXT = $2 AND purpose = $3 AND used_at IS NULL AND expires_at > NOW())
+    `, claims.Challenge, claims.Subject, claims.Purpose).Scan(&open)
+    if err != nil {
+        return nil, err
+    }
+    if !open {
+        return nil, fmt.Errorf("challenge token already used")
+    }
+    return &adminChallenge{id: claims.Challenge, adminID: claims.Subject, purpose: claims.Purpose}, nil
+}
+
+// consumeAdminChallenge marks a challenge used; false means another request used it first
+func consumeAdminChallenge(challengeID string) (bool, error) {
+    res, err := database.DB.Exec(`
+        UPDATE admin_login_challenges SET used_at = NOW() WHERE id::TEXT = $1 AND used_at IS NULL
+    `, challengeID)
+    if err != nil {
+        return false, err
+    }
+    n, _ := res.RowsAffected()
+    return n == 1, nil
+}
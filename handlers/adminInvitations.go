@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"models/auth"
+	"models/database"
+	"models/outbox"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultInvitationTTL = 72 * time.Hour
+	maxInvitationTTL     = 30 * 24 * time.Hour
+)
+
+// createAdmin inserts an admin row. exec is either the DB or a transaction.
+func createAdmin(exec interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, username, email, password, fullName, role string) (string, error) {
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+
+	var adminID string
+	err = exec.QueryRow(`
+		INSERT INTO admins (username, email, password_hash, full_name, role)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, username, email, hashedPassword, fullName, role).Scan(&adminID)
+	return adminID, err
+}
+
+// invitationLink builds the link emailed to the invitee. ADMIN_INVITE_URL is the
+// page of the admin app that accepts invitations.
+func invitationLink(token string) string {
+	return linkWithToken(os.Getenv("ADMIN_INVITE_URL"), token)
+}
+
+// linkWithToken appends the token as a query parameter, or returns "" when no base URL is configured
+func linkWithToken(base, token string) string {
+	if base == "" {
+		return ""
+	}
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
+
+func sendAdminInvitationEmail(q outbox.Execer, invitationID, email, lang, role, token string, expiresAt time.Time) error {
+	return queueCodeEmail(q, "admin_invitation:"+invitationID, email, lang, "admin_invitation", map[string]interface{}{
+		"Role":      role,
+		"Link":      invitationLink(token),
+		"Code":      token,
+		"ExpiresAt": expiresAt,
+	}, expiresAt)
+}
+
+// POST /api/admin/invitations
+func AdminCreateInvitation(c *gin.Context) {
+	invitedBy := c.MustGet("admin_id").(string)
+
+	var req AdminInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A valid email and a role are required"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	if !isValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown role: %s", req.Role)})
+		return
+	}
+
+	ttl := defaultInvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl > maxInvitationTTL {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invitations can be valid for at most 30 days"})
+		return
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	var exists bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE LOWER(email) = $1)
+	`, req.Email).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "An admin with this email already exists"})
+		return
+	}
+
+	token, tokenHash, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation"})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// A new invitation replaces any pending one for the same email
+	_, err = tx.Exec(`
+		UPDATE admin_invitations SET revoked_at = NOW()
+		WHERE email = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, req.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	var invitationID string
+	err = tx.QueryRow(`
+		INSERT INTO admin_invitations (email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, req.Email, req.Role, tokenHash, invitedBy, expiresAt).Scan(&invitationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		fmt.Println("Invitation error:", err)
+		return
+	}
+
+	if err := sendAdminInvitationEmail(tx, invitationID, req.Email, requestLanguage(c), req.Role, token, expiresAt); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send invitation email"})
+		fmt.Println("Invitation email error:", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Invitation sent",
+		"invitation_id": invitationID,
+		"email":         req.Email,
+		"role":          req.Role,
+		"expires_at":    expiresAt,
+	})
+}
+
+// GET /api/admin/invitations
+// Lists pending invitations; ?all=true also returns accepted, revoked and expired ones.
+func AdminListInvitations(c *gin.Context) {
+	query := `
+		SELECT i.id, i.email, i.role, i.expires_at, i.accepted_at, i.revoked_at, i.created_at,
+		       COALESCE(a.username, '') AS invited_by
+		FROM admin_invitations i
+		LEFT JOIN admins a ON a.id = i.invited_by
+	`
+	if c.Query("all") != "true" {
+		query += " WHERE i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()"
+	}
+	query += " ORDER BY i.created_at DESC"
+
+	rows, err := database.DB.Query(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+	defer rows.Close()
+
+	invitations := []gin.H{}
+	for rows.Next() {
+		var (
+			id, email, role, invitedBy string
+			expiresAt, createdAt       time.Time
+			acceptedAt, revokedAt      sql.NullTime
+		)
+		if err := rows.Scan(&id, &email, &role, &expiresAt, &acceptedAt, &revokedAt, &createdAt, &invitedBy); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+
+		status := "pending"
+		switch {
+		case acceptedAt.Valid:
+			status = "accepted"
+		case revokedAt.Valid:
+			status = "revoked"
+		case time.Now().After(expiresAt):
+			status = "expired"
+		}
+
+		invitations = append(invitations, gin.H{
+			"id":         id,
+			"email":      email,
+			"role":       role,
+			"status":     status,
+			"invited_by": invitedBy,
+			"expires_at": expiresAt,
+			"created_at": createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations, "count": len(invitations)})
+}
+
+// DELETE /api/admin/invitations/:id
+func AdminRevokeInvitation(c *gin.Context) {
+	invitationID := c.Param("id")
+
+	res, err := database.DB.Exec(`
+		UPDATE admin_invitations SET revoked_at = NOW()
+		WHERE id::TEXT = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, invitationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending invitation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// POST /api/admin/invitations/accept
+// Public: the invitee sets their username and password with the emailed token.
+func AdminAcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var invitationID, email, role string
+	err = tx.QueryRow(`
+		SELECT id, email, role FROM admin_invitations
+		WHERE token_hash = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		FOR UPDATE
+	`, hashToken(req.Token)).Scan(&invitationID, &email, &role)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invitation"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if rejectWeakPassword(c, req.Password, email, req.Username) {
+		return
+	}
+
+	var taken bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM admins WHERE username = $1 OR LOWER(email) = $2)
+	`, req.Username, email).Scan(&taken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Admin with this username or email already exists"})
+		return
+	}
+
+	adminID, err := createAdmin(tx, req.Username, email, req.Password, req.FullName, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create admin"})
+		fmt.Println("Create admin error:", err)
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admin_invitations SET accepted_at = NOW(), accepted_admin_id = $1 WHERE id = $2
+	`, adminID, invitationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Admin account created, you can now log in",
+		"admin_id": adminID,
+		"role":     role,
+	})
+}
+
+// BootstrapSuperAdmin creates the first super admin from BOOTSTRAP_ADMIN_* env vars.
+// It only does anything while there is no active super admin, which is also the case
+// right after the legacy admins were turned into viewers, so the variables can be
+// removed once the first super admin exists.
+func BootstrapSuperAdmin() {
+	username := os.Getenv("BOOTSTRAP_ADMIN_USERNAME")
+	email := strings.ToLower(strings.TrimSpace(os.Getenv("BOOTSTRAP_ADMIN_EMAIL")))
+	password := os.Getenv("BOOTSTRAP_ADMIN_PASSWORD")
+
+	var count int
+	err := database.DB.QueryRow(`
+		SELECT COUNT(*) FROM admins WHERE role = $1 AND deleted = FALSE AND is_active = TRUE
+	`, RoleSuperAdmin).Scan(&count)
+	if err != nil {
+		log.Fatal("Failed to count super admins: ", err)
+	}
+	if count > 0 {
+		return
+	}
+
+	if username == "" || email == "" || password == "" {
+		log.Println("No super admin exists yet. Set BOOTSTRAP_ADMIN_USERNAME, BOOTSTRAP_ADMIN_EMAIL and BOOTSTRAP_ADMIN_PASSWORD to create the first super admin.")
+		return
+	}
+
+	if failures := checkPassword(password, email, username); len(failures) > 0 {
+		for _, f := range failures {
+			log.Println("BOOTSTRAP_ADMIN_PASSWORD:", f.Message)
+		}
+		log.Fatal("Bootstrap super admin password does not meet the password policy")
+	}
+
+	adminID, err := createAdmin(database.DB, username, email, password, os.Getenv("BOOTSTRAP_ADMIN_FULL_NAME"), RoleSuperAdmin)
+	if err != nil {
+		log.Fatal("Failed to create bootstrap super admin: ", err)
+	}
+	log.Printf("Created first super admin %s (%s)", username, adminID)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Admin roles stored in admins.role
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleReviewer   = "reviewer"
+	RoleEditor     = "editor"
+	RoleViewer     = "viewer"
+)
+
+// Permissions checked on admin routes
+const (
+	PermViewTalents     = "talents.view"
+	PermReviewTalents   = "talents.review" // approve / reject
+	PermEditTalents     = "talents.edit"
+	PermDeleteTalents   = "talents.delete"
+	PermManageSettings  = "settings.manage"
+	PermManageAdmins    = "admins.manage"    // invitations and admin accounts
+	PermManageAccounts  = "accounts.manage"  // login history and unlocking locked accounts
+	PermManageInquiries = "inquiries.manage" // contact form leads
+)
+
+var rolePermissions = map[string][]string{
+	RoleSuperAdmin: {PermViewTalents, PermReviewTalents, PermEditTalents, PermDeleteTalents, PermManageSettings, PermManageAdmins, PermManageAccounts, PermManageInquiries},
+	RoleReviewer:   {PermViewTalents, PermReviewTalents},
+	RoleEditor:     {PermViewTalents, PermEditTalents},
+	RoleViewer:     {PermViewTalents},
+}
+
+func isValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func hasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionsFor returns the permissions of a role, never nil so it encodes as []
+func permissionsFor(role string) []string {
+	perms := rolePermissions[role]
+	if perms == nil {
+		return []string{}
+	}
+	return perms
+}
+
+// RequirePermission guards an admin route. It runs after AdminAuthMiddleware,
+// which loads the admin's current role into the context.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		if !hasPermission(role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":              "Missing permission: " + permission,
+				"missing_permission": permission,
+				"role":               role,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"models/auth"
+	"models/database"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	recoveryCodeCount = 10
+	// How long the second step stays locked after too many wrong codes
+	twoFactorLockout = 15 * time.Minute
+)
+
+var (
+	errTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	errTOTPNotStarted     = errors.New("two-factor setup has not been started")
+	errInvalidTOTPCode    = errors.New("invalid authentication code")
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// beginTOTPEnrolment stores a fresh, not yet enabled secret for the admin
+func beginTOTPEnrolment(adminID string) (gin.H, error) {
+	var username string
+	var enabled bool
+	err := database.DB.QueryRow(`
+		SELECT username, totp_enabled FROM admins WHERE id = $1 AND deleted = FALSE
+	`, adminID).Scan(&username, &enabled)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := auth.EncryptSecret(secret, adminID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = database.DB.Exec(`
+		UPDATE admins SET totp_secret = $1, totp_last_step = 0, updated_at = NOW() WHERE id = $2
+	`, stored, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"secret":           secret,
+		"provisioning_uri": totpProvisioningURI(secret, username),
+	}, nil
+}
+
+// confirmTOTPEnrolment enables 2FA once the first code from the app matches,
+// and returns a fresh set of recovery codes
+func confirmTOTPEnrolment(adminID, code string) ([]string, error) {
+	tx, err := database.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var secret sql.NullString
+	var enabled bool
+	var lastStep int64
+	err = tx.QueryRow(`
+		SELECT totp_secret, totp_enabled, totp_last_step FROM admins WHERE id = $1 FOR UPDATE
+	`, adminID).Scan(&secret, &enabled, &lastStep)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+	if !secret.Valid || secret.String == "" {
+		return nil, errTOTPNotStarted
+	}
+	plain, err := openTOTPSecret(secret.String, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := verifyTOTP(plain, code, lastStep)
+	if !ok {
+		return nil, errInvalidTOTPCode
+	}
+
+	_, err = tx.Exec(`
+		UPDATE admins SET totp_enabled = TRUE, totp_last_step = $1, updated_at = NOW() WHERE id = $2
+	`, step, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, tx.Commit()
+}
+
+// replaceRecoveryCodes drops the admin's recovery codes and stores new ones.
+// Only hashes are kept, so the plain codes are returned to show once.
+func replaceRecoveryCodes(tx *sql.Tx, adminID string) ([]string, error) {
+	if _, err := tx.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, adminID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(totpEncoding.EncodeToString(buf))
+		code := raw[:4] + "-" + raw[4:]
+
+		_, err := tx.Exec(`
+			INSERT INTO admin_recovery_codes (admin_id, code_hash) VALUES ($1, $2)
+		`, adminID, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// openTOTPSecret decrypts a stored TOTP secret. Secrets from before encryption are
+// still accepted until EncryptStoredTOTPSecrets has run.
+func openTOTPSecret(stored, adminID string) (string, error) {
+	secret, err := auth.DecryptSecret(stored, adminID)
+	if err == auth.ErrSecretNotEncrypted {
+		return secret, nil
+	}
+	return secret, err
+}
+
+// EncryptStoredTOTPSecrets encrypts TOTP secrets that were stored in plain text.
+// It runs at startup and does nothing once every secret is encrypted.
+func EncryptStoredTOTPSecrets() {
+	rows, err := database.DB.Query(`
+		SELECT id::TEXT, totp_secret FROM admins WHERE totp_secret IS NOT NULL AND totp_secret NOT LIKE 'enc1:%'
+	`)
+	if err != nil {
+		log.Println("Failed to read TOTP secrets:", err)
+		return
+	}
+	type plainSecret struct{ adminID, secret string }
+	var pending []plainSecret
+	for rows.Next() {
+		var p plainSecret
+		if err := rows.Scan(&p.adminID, &p.secret); err == nil {
+			pending = append(pending, p)
+		}
+	}
+	rows.Close()
+
+	for _, p := range pending {
+		stored, err := auth.EncryptSecret(p.secret, p.adminID)
+		if err == nil {
+			_, err = database.DB.Exec(`
+				UPDATE admins SET totp_secret = $1 WHERE id::TEXT = $2 AND totp_secret = $3
+			`, stored, p.adminID, p.secret)
+		}
+		if err != nil {
+			log.Printf("Failed to encrypt the TOTP secret of admin %s: %v", p.adminID, err)
+		}
+	}
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// checkSecondFactor accepts either a current TOTP code or an unused recovery code
+func checkSecondFactor(adminID, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		res, err := database.DB.Exec(`
+			UPDATE admin_recovery_codes SET used_at = NOW()
+			WHERE admin_id = $1 AND code_hash = $2 AND used_at IS NULL
+		`, adminID, hashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return false, err
+		}
+		n, _ := res.RowsAffected()
+		return n == 1, nil
+	}
+
+	var secret sql.NullString
+	var lastStep int64
+	err := database.DB.QueryRow(`
+		SELECT totp_secret, totp_last_step FROM admins WHERE id = $1 AND totp_enabled = TRUE
+	`, adminID).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	plain, err := openTOTPSecret(secret.String, adminID)
+	if err != nil {
+		return false, err
+	}
+	step, ok := verifyTOTP(plain, code, lastStep)
+	if !ok {
+		return false, nil
+	}
+
+	// Only one request can move the step forward, so a code works once
+	res, err := database.DB.Exec(`
+		UPDATE admins SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1
+	`, step, adminID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+func respondTwoFactorError(c *gin.Context, err error) {
+	switch err {
+	case errTOTPAlreadyEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	case errTOTPNotStarted:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+	case errInvalidTOTPCode:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		fmt.Println("Two-factor error:", err)
+	}
+}
+
+// POST /api/admin/2fa/setup
+func AdminSetup2FA(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	setup, err := beginTOTPEnrolment(adminID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	setup["message"] = "Scan the QR code with your authenticator app, then confirm with a code"
+	c.JSON(http.StatusOK, setup)
+}
+
+// POST /api/admin/2fa/confirm
+func AdminConfirm2FA(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authentication code is required"})
+		return
+	}
+
+	codes, err := confirmTOTPEnrolment(adminID, req.Code)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// POST /api/admin/2fa/disable
+func AdminDisable2FA(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password and authentication code are required"})
+		return
+	}
+
+	if getBoolSetting(SettingRequireAdmin2FA) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is mandatory for all admins"})
+		return
+	}
+
+	var passwordHash string
+	err := database.DB.QueryRow(`SELECT password_hash FROM admins WHERE id = $1`, adminID).Scan(&passwordHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ok, _, _ := auth.VerifyPassword(passwordHash, req.Password); !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
+	}
+
+	ok, err := checkSecondFactor(adminID, req.Code, "")
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	if !ok {
+		respondTwoFactorError(c, errInvalidTOTPCode)
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE admins SET totp_enabled = FALSE, totp_secret = NULL, totp_last_step = 0, updated_at = NOW()
+		WHERE id = $1
+	`, adminID)
+	if err == nil {
+		_, err = database.DB.Exec(`DELETE FROM admin_recovery_codes WHERE admin_id = $1`, adminID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// POST /api/admin/2fa/recovery-codes
+// Replaces all recovery codes; needs a current authenticator code.
+func AdminRegenerateRecoveryCodes(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Authentication code is required"})
+		return
+	}
+
+	ok, err := checkSecondFactor(adminID, req.Code, "")
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+	if !ok {
+		respondTwoFactorError(c, errInvalidTOTPCode)
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, adminID)
+	if err != nil || tx.Commit() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// POST /api/admin/login/2fa/setup
+// Enrolment during login, for admins who must use 2FA but have not set it up yet.
+func AdminLoginSetup2FA(c *gin.Context) {
+	var req struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge token is required"})
+		return
+	}
+
+	challenge, err := parseAdminChallenge(req.ChallengeToken)
+	if err != nil || challenge.purpose != challengeEnroll2FA {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	setup, err := beginTOTPEnrolment(challenge.adminID)
+	if err != nil {
+		respondTwoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// POST /api/admin/login/2fa
+// Second login step: exchanges the challenge token and a code for the admin JWT.
+// The challenge token is used up by a successful login.
+// For an enrolment challenge the code also confirms the new authenticator.
+func AdminLoginVerify2FA(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (req.Code == "" && req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Challenge token and code or recovery code are required"})
+		return
+	}
+
+	challenge, err := parseAdminChallenge(req.ChallengeToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+	adminID, purpose := challenge.adminID, challenge.purpose
+
+	var admin Admin
+	var lockedUntil sql.NullTime
+	err = database.DB.QueryRow(`
+		SELECT id, username, email, full_name, role, is_active, totp_locked_until
+		FROM admins WHERE id = $1 AND deleted = FALSE
+	`, adminID).Scan(&admin.ID, &admin.Username, &admin.Email, &admin.FullName,
+		&admin.Role, &admin.IsActive, &lockedUntil)
+	if err != nil || !admin.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	if lockedUntil.Valid && time.Now().Before(lockedUntil.Time) {
+		respondTooManyRequests(c, "two_factor_locked", "Too many invalid codes, try again later", time.Until(lockedUntil.Time))
+		return
+	}
+
+	var extra gin.H
+	if purpose == challengeEnroll2FA {
+		codes, err := confirmTOTPEnrolment(adminID, req.Code)
+		if err != nil && err != errInvalidTOTPCode {
+			respondTwoFactorError(c, err)
+			return
+		}
+		if err == nil {
+			extra = gin.H{"recovery_codes": codes}
+		}
+	} else {
+		ok, err := checkSecondFactor(adminID, req.Code, req.RecoveryCode)
+		if err != nil {
+			respondTwoFactorError(c, err)
+			return
+		}
+		if ok {
+			extra = gin.H{}
+		}
+	}
+
+	if extra == nil {
+		// Count the failure and lock the second step once the limit is reached
+		var failures int
+		err = database.DB.QueryRow(`
+			UPDATE admins
+			SET totp_failed_attempts = totp_failed_attempts + 1,
+			    totp_locked_until = CASE WHEN totp_failed_attempts + 1 >= $2 THEN $3 ELSE totp_locked_until END
+			WHERE id = $1
+			RETURNING totp_failed_attempts
+		`, adminID, maxOTPAttempts, time.Now().Add(twoFactorLockout)).Scan(&failures)
+		if err == nil && failures >= maxOTPAttempts {
+			// The lockout also ends this challenge, the password step has to be passed again
+			database.DB.Exec(`UPDATE admins SET totp_failed_attempts = 0 WHERE id = $1`, adminID)
+			consumeAdminChallenge(challenge.id)
+			respondTooManyRequests(c, "two_factor_locked", "Too many invalid codes, try again later", twoFactorLockout)
+			return
+		}
+		respondTwoFactorError(c, errInvalidTOTPCode)
+		return
+	}
+
+	// The challenge completes one login only
+	if ok, err := consumeAdminChallenge(challenge.id); err != nil || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	database.DB.Exec(`UPDATE admins SET totp_failed_attempts = 0, totp_locked_until = NULL WHERE id = $1`, adminID)
+	respondAdminLogin(c, admin, extra)
+}
@@ -1,14 +1,20 @@
 package handlers
 
 import (
+	"database/sql"
 	"fmt"
+	"models/database"
+	"models/mailer"
+	"models/outbox"
 	"net/http"
-	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/mailjet/mailjet-apiv3-go"
 )
 
+// At most one auto-reply per address in this interval
+const contactAutoReplyInterval = time.Minute
+
 type ContactForm struct {
 	Name      string `json:"name"`
 	Email     string `json:"email"`
@@ -17,6 +23,14 @@ type ContactForm struct {
 	EventType string `json:"eventType"`
 	EventDate string `json:"eventDate"`
 	Message   string `json:"message"`
+	// IDs or usernames of approved talents the client is interested in
+	Talents []string `json:"talents"`
+
+	// Bot checks, see guardContactForm
+	Website      string `json:"website"`         // honeypot, hidden from humans and left empty
+	FormToken    string `json:"form_token"`      // from GET /api/contact/form-token when the form was shown
+	StartedAt    int64  `json:"form_started_at"` // Deprecated: Unix milliseconds, sent by clients without form_token
+	CaptchaToken string `json:"captcha_token"`
 }
 
 // POST /api/contact
@@ -37,79 +51,131 @@ func HandleContact(ctx *gin.Context) {
 		return
 	}
 
-	// ✅ Load Mailjet credentials from environment
-	apiKey := os.Getenv("MAILJET_API_KEY")
-	apiSecret := os.Getenv("MAILJET_API_SECRET")
-	senderEmail := os.Getenv("MAILJET_SENDER_EMAIL")
-	senderName := os.Getenv("MAILJET_SENDER_NAME")
+	// ✅ Honeypot, timing, rate limit and captcha checks
+	if !guardContactForm(ctx, form) {
+		return
+	}
 
-	if apiKey == "" || apiSecret == "" || senderEmail == "" {
-		fmt.Println("Error: Missing Mailjet environment variables")
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Email service not configured properly. Missing environment variables.",
+	// ✅ Talents asked about must be approved profiles
+	if len(form.Talents) > maxInquiryTalents {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("At most %d talents can be included in one message", maxInquiryTalents),
+		})
+		return
+	}
+	talents, unknown, err := resolveInquiryTalents(form.Talents)
+	if err != nil {
+		fmt.Println("Talent lookup error:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message. Please try again later."})
+		return
+	}
+	if len(unknown) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Some of the selected talents are not available",
+			"code":    "unknown_talent",
+			"talents": unknown,
 		})
 		return
 	}
 
-	mailjetClient := mailjet.NewMailjetClient(apiKey, apiSecret)
-
-	// ✅ Construct the email that goes to the agency
-	subject := fmt.Sprintf("📩 New Contact Form Submission from %s", form.Name)
-	body := fmt.Sprintf(`
-You have received a new contact form submission.
-
---------------------------------------
-Name: %s
-Email: %s
-Phone: %s
-Company: %s
-Event Type: %s
-Event Date: %s
-
-Message:
-%s
---------------------------------------
-
-This message was submitted from the website contact form.
-`, form.Name, form.Email, form.Phone, form.Company, form.EventType, form.EventDate, form.Message)
-
-	// ✅ Send email to your agency inbox (MAILJET_SENDER_EMAIL)
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: senderEmail, // From your agency email
-				Name:  senderName,
-			},
-			To: &mailjet.RecipientsV31{
-				{
-					Email: senderEmail, // Send TO your agency inbox
-					Name:  "Agency Inbox",
-				},
-			},
-			Subject:  subject,
-			TextPart: body,
-			ReplyTo: &mailjet.RecipientV31{ // ✅ So you can reply directly to the user
-				Email: form.Email,
-				Name:  form.Name,
-			},
-		},
-	}
-
-	messages := mailjet.MessagesV31{Info: messagesInfo}
-	response, err := mailjetClient.SendMailV31(&messages)
+	// ✅ Save the enquiry as a lead; the email to the agency inbox is queued with it
+	tx, err := database.DB.Begin()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message. Please try again later."})
+		return
+	}
+	defer tx.Rollback()
+
+	var inquiryID, reference string
+	err = tx.QueryRow(`
+		INSERT INTO inquiries (name, email, phone, company, event_type, event_date, message, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, reference
+	`, form.Name, form.Email, form.Phone, form.Company, form.EventType, form.EventDate, form.Message, ctx.ClientIP()).Scan(&inquiryID, &reference)
 	if err != nil {
-		fmt.Printf("Mailjet error: %v\n", err)
+		fmt.Println("Inquiry insert error:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message. Please try again later."})
+		return
+	}
+
+	if err := saveInquiryTalents(tx, inquiryID, talents); err != nil {
+		fmt.Println("Inquiry talents insert error:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message. Please try again later."})
+		return
+	}
+
+	// ✅ Build the notification for the agency inbox (the configured sender address)
+	msg, err := mailer.Render("contact_notification", mailer.DefaultLanguage(), map[string]interface{}{
+		"Name":      form.Name,
+		"Email":     form.Email,
+		"Phone":     form.Phone,
+		"Company":   form.Company,
+		"EventType": form.EventType,
+		"EventDate": form.EventDate,
+		"Message":   form.Message,
+		"Reference": reference,
+		"Talents":   talents,
+	})
+	if err == nil {
+		msg.To = []mailer.Address{{Email: mailer.From().Email, Name: "Agency Inbox"}}
+		msg.ReplyTo = &mailer.Address{Email: form.Email, Name: form.Name} // ✅ So you can reply directly to the user
+		err = outbox.Enqueue(tx, "contact_notification:"+inquiryID, msg)
+	}
+	if err == nil {
+		err = queueContactAcknowledgement(ctx, tx, inquiryID, reference, form)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		fmt.Printf("Contact form error: %v\n", err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to send email. Please try again later.",
-			"details": err.Error(),
+			"error": "Failed to send message. Please try again later.",
 		})
 		return
 	}
 
-	fmt.Printf("Contact email successfully sent to agency inbox! Response: %+v\n", response)
+	fmt.Println("Contact enquiry saved:", inquiryID)
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "Message sent successfully! We'll get back to you soon.",
+		"status":    "success",
+		"message":   "Message sent successfully! We'll get back to you soon.",
+		"reference": reference,
+	})
+}
+
+// queueContactAcknowledgement sends the sender their reference number, unless the
+// agency turned auto-replies off or the address already got one in the last minute
+func queueContactAcknowledgement(ctx *gin.Context, tx *sql.Tx, inquiryID, reference string, form ContactForm) error {
+	if !getBoolSetting(SettingContactAutoReply) {
+		return nil
+	}
+
+	// Concurrent submissions from the same address queue up on this lock until the
+	// first one commits, so they see its acknowledged_at
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext(LOWER($1)))`, form.Email); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE inquiries SET acknowledged_at = NOW()
+		WHERE id = $1 AND NOT EXISTS (
+			SELECT 1 FROM inquiries
+			WHERE LOWER(email) = LOWER($2) AND id <> $1 AND acknowledged_at > NOW() - $3 * INTERVAL '1 second'
+		)
+	`, inquiryID, form.Email, int(contactAutoReplyInterval.Seconds()))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		fmt.Println("Contact auto-reply skipped, one was sent to this address less than a minute ago")
+		return nil
+	}
+
+	return queueTemplateEmail(tx, "contact_acknowledgement:"+inquiryID, form.Email, requestLanguage(ctx), "contact_acknowledgement", map[string]interface{}{
+		"Name":      form.Name,
+		"Reference": reference,
+		"EventType": form.EventType,
+		"EventDate": form.EventDate,
 	})
 }
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"models/auth"
+	"models/captcha"
+	"models/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits on the public contact form
+const (
+	contactMinSubmitTime = 3 * time.Second // humans take longer than this to fill in the form
+	contactFormTokenTTL  = 2 * time.Hour   // a form left open longer has to be reloaded
+	contactMaxPerIP      = 5
+	contactIPWindow      = time.Hour
+	contactMaxPerEmail   = 3
+	contactEmailWindow   = 24 * time.Hour
+)
+
+// Reasons stored with rejected contact submissions
+const (
+	ContactRejectedHoneypot     = "honeypot"
+	ContactRejectedTooFast      = "too_fast"
+	ContactRejectedFormToken    = "invalid_form_token"
+	ContactRejectedIPLimit      = "ip_rate_limit"
+	ContactRejectedEmailLimit   = "email_rate_limit"
+	ContactRejectedCaptcha      = "captcha_failed"
+	ContactRejectedCaptchaError = "captcha_error"
+)
+
+// GET /api/contact/form-token
+// Called when the contact form is shown. The signed token records the time, so the
+// submit can be checked for being too fast without trusting a client clock.
+func ContactFormToken(ctx *gin.Context) {
+	token, err := auth.Issue("contact", auth.AudienceContactForm, contactFormTokenTTL, auth.Claims{})
+	if err != nil {
+		fmt.Println("Contact form token error:", err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare the form"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"form_token": token,
+		"expires_in": int(contactFormTokenTTL.Seconds()),
+	})
+}
+
+// contactFormAge returns how long ago the form was shown. Clients from before
+// form_token still send form_started_at and, failing that, nothing; both are
+// accepted for one release so they keep working until they are updated.
+func contactFormAge(form ContactForm) (time.Duration, error) {
+	switch {
+	case form.FormToken != "":
+		claims, err := auth.Verify(form.FormToken, auth.AudienceContactForm)
+		if err != nil {
+			return 0, err
+		}
+		if claims.IssuedAt == nil {
+			return 0, errors.New("form token has no issue time")
+		}
+		return time.Since(claims.IssuedAt.Time), nil
+	case form.StartedAt != 0:
+		return time.Since(time.UnixMilli(form.StartedAt)), nil
+	default:
+		return contactMinSubmitTime, nil
+	}
+}
+
+// logContactRejection keeps a rejected submission so admins can check for false positives
+func logContactRejection(ctx *gin.Context, form ContactForm, reason string) {
+	_, err := database.DB.Exec(`
+		INSERT INTO contact_rejections (reason, ip_address, user_agent, name, email, message)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, reason, ctx.ClientIP(), ctx.Request.UserAgent(), form.Name, form.Email, form.Message)
+	if err != nil {
+		fmt.Println("Failed to log contact rejection:", err)
+	}
+	fmt.Printf("Contact form rejected (%s) from %s\n", reason, ctx.ClientIP())
+}
+
+// recentInquiries counts the inquiries matching column = value inside the window and
+// returns how long until the oldest of them leaves it
+func recentInquiries(column, value string, window time.Duration) (int, time.Duration, error) {
+	var (
+		count  int
+		oldest sql.NullTime
+	)
+	err := database.DB.QueryRow(fmt.Sprintf(`
+		SELECT COUNT(*), MIN(created_at) FROM inquiries
+		WHERE LOWER(%s) = LOWER($1) AND created_at > $2
+	`, column), value, time.Now().Add(-window)).Scan(&count, &oldest)
+	if err != nil || !oldest.Valid {
+		return count, 0, err
+	}
+	return count, time.Until(oldest.Time.Add(window)), nil
+}
+
+// guardContactForm runs the bot and abuse checks. When it returns false the
+// submission was logged as rejected and the response has been written.
+func guardContactForm(ctx *gin.Context, form ContactForm) bool {
+	// Bots fill in every field; they are told it worked so they do not adapt
+	if form.Website != "" {
+		logContactRejection(ctx, form, ContactRejectedHoneypot)
+		ctx.JSON(http.StatusOK, gin.H{
+			"status":  "success",
+			"message": "Message sent successfully! We'll get back to you soon.",
+		})
+		return false
+	}
+
+	age, err := contactFormAge(form)
+	if err != nil {
+		logContactRejection(ctx, form, ContactRejectedFormToken)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "The form has expired, please reload the page and try again",
+			"code":  ContactRejectedFormToken,
+		})
+		return false
+	}
+	if age < contactMinSubmitTime {
+		logContactRejection(ctx, form, ContactRejectedTooFast)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "The form was submitted too quickly, please try again",
+			"code":  ContactRejectedTooFast,
+		})
+		return false
+	}
+
+	limits := []struct {
+		column, value, reason string
+		max                   int
+		window                time.Duration
+	}{
+		{"ip_address", ctx.ClientIP(), ContactRejectedIPLimit, contactMaxPerIP, contactIPWindow},
+		{"email", form.Email, ContactRejectedEmailLimit, contactMaxPerEmail, contactEmailWindow},
+	}
+	for _, limit := range limits {
+		count, wait, err := recentInquiries(limit.column, limit.value, limit.window)
+		if err != nil {
+			fmt.Println("Contact rate limit error:", err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message. Please try again later."})
+			return false
+		}
+		if count >= limit.max {
+			logContactRejection(ctx, form, limit.reason)
+			respondTooManyRequests(ctx, limit.reason, "Too many messages sent, please try again later", wait)
+			return false
+		}
+	}
+
+	ok, err := captcha.Verify(form.CaptchaToken, ctx.ClientIP())
+	if err != nil {
+		fmt.Println("Captcha verification error:", err)
+		logContactRejection(ctx, form, ContactRejectedCaptchaError)
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Could not verify the captcha, please try again later",
+			"code":  ContactRejectedCaptchaError,
+		})
+		return false
+	}
+	if !ok {
+		logContactRejection(ctx, form, ContactRejectedCaptcha)
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Captcha verification failed",
+			"code":  ContactRejectedCaptcha,
+		})
+		return false
+	}
+
+	return true
+}
+
+// GET /api/admin/inquiries/rejected
+// Optional filters: reason, hours (default 168), limit (default 100).
+func AdminListContactRejections(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "168"))
+	if err != nil || hours <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be a positive number"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	query := `
+		SELECT id, reason, ip_address, user_agent, name, email, message, created_at
+		FROM contact_rejections
+		WHERE created_at > NOW() - $1::INTERVAL
+	`
+	args := []interface{}{fmt.Sprintf("%d hours", hours)}
+	if reason := c.Query("reason"); reason != "" {
+		args = append(args, reason)
+		query += fmt.Sprintf(" AND reason = $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rejected submissions"})
+		fmt.Println("Contact rejections query error:", err)
+		return
+	}
+	defer rows.Close()
+
+	rejections := []gin.H{}
+	for rows.Next() {
+		var (
+			id, reason, ip, userAgent, name, email, message string
+			createdAt                                       time.Time
+		)
+		if err := rows.Scan(&id, &reason, &ip, &userAgent, &name, &email, &message, &createdAt); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		rejections = append(rejections, gin.H{
+			"id":          id,
+			"reason":      reason,
+			"ip_address":  ip,
+			"user_agent":  userAgent,
+			"name":        name,
+			"email":       email,
+			"message":     message,
+			"rejected_at": createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rejections": rejections, "count": len(rejections)})
+}
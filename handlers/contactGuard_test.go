@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"models/auth"
+	"testing"
+	"time"
+)
+
+func TestContactFormAge(t *testing.T) {
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SECRET", "contact-form-test-secret-of-32-characters")
+	t.Setenv("TOTP_ENCRYPTION_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	if err := auth.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	fresh, err := auth.Issue("contact", auth.AudienceContactForm, contactFormTokenTTL, auth.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := auth.Issue("contact", auth.AudienceContactForm, -time.Minute, auth.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAudience, err := auth.Issue("1", auth.AudienceUser, time.Minute, auth.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		form      ContactForm
+		tooFast   bool
+		wantError bool
+	}{
+		{name: "token just issued", form: ContactForm{FormToken: fresh}, tooFast: true},
+		{name: "expired token", form: ContactForm{FormToken: expired}, wantError: true},
+		{name: "token for another audience", form: ContactForm{FormToken: otherAudience}, wantError: true},
+		{name: "forged token", form: ContactForm{FormToken: "not.a.token"}, wantError: true},
+		{name: "token wins over a client time", form: ContactForm{FormToken: fresh, StartedAt: time.Now().Add(-time.Hour).UnixMilli()}, tooFast: true},
+		{name: "old client, time given", form: ContactForm{StartedAt: time.Now().Add(-time.Minute).UnixMilli()}},
+		{name: "old client, time too recent", form: ContactForm{StartedAt: time.Now().UnixMilli()}, tooFast: true},
+		{name: "old client, nothing given", form: ContactForm{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			age, err := contactFormAge(tt.form)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("contactFormAge() error = %v, want error %v", err, tt.wantError)
+			}
+			if err == nil && (age < contactMinSubmitTime) != tt.tooFast {
+				t.Errorf("contactFormAge() = %s, want too fast %v", age, tt.tooFast)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"models/database"
+	"models/mailer"
+	"models/outbox"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queueTemplateEmail renders one of the mailer templates in lang and queues it for a single recipient.
+// Pass the transaction of the change the email is about so both are committed together;
+// key must identify the email, e.g. "welcome:<userid>", so that it is never queued twice.
+func queueTemplateEmail(q outbox.Execer, key, to, lang, template string, data map[string]interface{}) error {
+	msg, err := mailer.Render(template, lang, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []mailer.Address{{Email: to}}
+	return outbox.Enqueue(q, key, msg)
+}
+
+// queueCodeEmail is queueTemplateEmail for an email carrying a code or link that stops
+// working at expiresAt; an email still unsent by then is dropped from the outbox.
+func queueCodeEmail(q outbox.Execer, key, to, lang, template string, data map[string]interface{}, expiresAt time.Time) error {
+	msg, err := mailer.Render(template, lang, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []mailer.Address{{Email: to}}
+	return outbox.EnqueueExpiring(q, key, msg, expiresAt)
+}
+
+// requestLanguage picks the email language from the request's Accept-Language header
+func requestLanguage(c *gin.Context) string {
+	return mailer.MatchLanguage(c.GetHeader("Accept-Language"))
+}
+
+// userLanguage is the user's stored language, or the request's when none is stored
+func userLanguage(c *gin.Context, email string) string {
+	var lang string
+	database.DB.QueryRow(`SELECT COALESCE(preferred_language, '') FROM users WHERE email = $1`, email).Scan(&lang)
+	if mailer.IsSupportedLanguage(lang) {
+		return lang
+	}
+	return requestLanguage(c)
+}
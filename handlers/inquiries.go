@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/database"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Inquiry statuses, in pipeline order
+const (
+	InquiryNew       = "new"
+	InquiryContacted = "contacted"
+	InquiryQuoted    = "quoted"
+	InquiryWon       = "won"
+	InquiryLost      = "lost"
+)
+
+var inquiryStatuses = []string{InquiryNew, InquiryContacted, InquiryQuoted, InquiryWon, InquiryLost}
+
+type InquiryStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// AdminID empty unassigns the inquiry
+type InquiryAssignRequest struct {
+	AdminID string `json:"admin_id"`
+}
+
+type InquiryNoteRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+const inquiryColumns = `
+	i.id, i.reference, i.name, i.email, i.phone, i.company, i.event_type, i.event_date, i.message,
+	i.status, i.assigned_to, COALESCE(a.username, ''), i.created_at, i.updated_at
+`
+
+func scanInquiry(row interface{ Scan(...interface{}) error }) (gin.H, error) {
+	var (
+		id, reference, name, email, phone, company, eventType, eventDate, message, status, assigneeName string
+		assignedTo                                                                                      sql.NullString
+		createdAt, updatedAt                                                                            time.Time
+	)
+	err := row.Scan(&id, &reference, &name, &email, &phone, &company, &eventType, &eventDate, &message,
+		&status, &assignedTo, &assigneeName, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignee interface{}
+	if assignedTo.Valid {
+		assignee = gin.H{"id": assignedTo.String, "username": assigneeName}
+	}
+	return gin.H{
+		"id":          id,
+		"reference":   reference,
+		"name":        name,
+		"email":       email,
+		"phone":       phone,
+		"company":     company,
+		"event_type":  eventType,
+		"event_date":  eventDate,
+		"message":     message,
+		"status":      status,
+		"assigned_to": assignee,
+		"created_at":  createdAt,
+		"updated_at":  updatedAt,
+	}, nil
+}
+
+// GET /api/admin/inquiries
+// Filters: status, assigned_to (an admin id, "me" or "none"), q (reference, name, email or company), page, limit.
+func AdminListInquiries(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive number"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	where := " WHERE TRUE"
+	var args []interface{}
+
+	if status := c.Query("status"); status != "" {
+		if !slices.Contains(inquiryStatuses, status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status", "allowed_statuses": inquiryStatuses})
+			return
+		}
+		args = append(args, status)
+		where += fmt.Sprintf(" AND i.status = $%d", len(args))
+	}
+	switch assignedTo := c.Query("assigned_to"); assignedTo {
+	case "":
+	case "none":
+		where += " AND i.assigned_to IS NULL"
+	case "me":
+		args = append(args, c.MustGet("admin_id").(string))
+		where += fmt.Sprintf(" AND i.assigned_to = $%d", len(args))
+	default:
+		args = append(args, assignedTo)
+		where += fmt.Sprintf(" AND i.assigned_to::TEXT = $%d", len(args))
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		args = append(args, "%"+q+"%")
+		where += fmt.Sprintf(" AND (i.reference ILIKE $%[1]d OR i.name ILIKE $%[1]d OR i.email ILIKE $%[1]d OR i.company ILIKE $%[1]d)", len(args))
+	}
+
+	var total int
+	if err := database.DB.QueryRow(`SELECT COUNT(*) FROM inquiries i`+where, args...).Scan(&total); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inquiries"})
+		fmt.Println("Inquiries count error:", err)
+		return
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	rows, err := database.DB.Query(`
+		SELECT `+inquiryColumns+`
+		FROM inquiries i LEFT JOIN admins a ON a.id = i.assigned_to
+	`+where+fmt.Sprintf(" ORDER BY i.created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inquiries"})
+		fmt.Println("Inquiries query error:", err)
+		return
+	}
+	defer rows.Close()
+
+	inquiries := []gin.H{}
+	var ids []string
+	for rows.Next() {
+		inquiry, err := scanInquiry(rows)
+		if err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		inquiries = append(inquiries, inquiry)
+		ids = append(ids, inquiry["id"].(string))
+	}
+
+	talents, err := loadInquiryTalents(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch inquiries"})
+		fmt.Println("Inquiry talents query error:", err)
+		return
+	}
+	for _, inquiry := range inquiries {
+		inquiry["talents"] = talentsOrEmpty(talents[inquiry["id"].(string)])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"inquiries":   inquiries,
+		"total_count": total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// talentsOrEmpty keeps inquiries without talents encoding as [] rather than null
+func talentsOrEmpty(talents []inquiryTalent) []inquiryTalent {
+	if talents == nil {
+		return []inquiryTalent{}
+	}
+	return talents
+}
+
+// GET /api/admin/inquiries/:id
+// Returns the inquiry with its notes, oldest first.
+func AdminGetInquiry(c *gin.Context) {
+	inquiryID := c.Param("id")
+
+	inquiry, err := scanInquiry(database.DB.QueryRow(`
+		SELECT `+inquiryColumns+`
+		FROM inquiries i LEFT JOIN admins a ON a.id = i.assigned_to
+		WHERE i.id::TEXT = $1
+	`, inquiryID))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inquiry not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT n.id, n.note, n.admin_id, COALESCE(a.username, ''), n.created_at
+		FROM inquiry_notes n LEFT JOIN admins a ON a.id = n.admin_id
+		WHERE n.inquiry_id = $1
+		ORDER BY n.created_at
+	`, inquiry["id"])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notes"})
+		return
+	}
+	defer rows.Close()
+
+	notes := []gin.H{}
+	for rows.Next() {
+		var (
+			id, note, author string
+			adminID          sql.NullString
+			createdAt        time.Time
+		)
+		if err := rows.Scan(&id, &note, &adminID, &author, &createdAt); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		notes = append(notes, gin.H{
+			"id":         id,
+			"note":       note,
+			"admin_id":   adminID.String,
+			"author":     author,
+			"created_at": createdAt,
+		})
+	}
+	inquiry["notes"] = notes
+
+	talents, err := loadInquiryTalents([]string{inquiry["id"].(string)})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch talents"})
+		return
+	}
+	inquiry["talents"] = talentsOrEmpty(talents[inquiry["id"].(string)])
+
+	c.JSON(http.StatusOK, gin.H{"inquiry": inquiry})
+}
+
+// PUT /api/admin/inquiries/:id/status
+// Any status can be set, so a lead marked lost by mistake can be reopened.
+func AdminUpdateInquiryStatus(c *gin.Context) {
+	var req InquiryStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status is required"})
+		return
+	}
+	if !slices.Contains(inquiryStatuses, req.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status", "allowed_statuses": inquiryStatuses})
+		return
+	}
+
+	res, err := database.DB.Exec(`
+		UPDATE inquiries SET status = $1, updated_at = NOW() WHERE id::TEXT = $2
+	`, req.Status, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update inquiry"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inquiry not found"})
+		return
+	}
+
+	AdminGetInquiry(c)
+}
+
+// PUT /api/admin/inquiries/:id/assign
+func AdminAssignInquiry(c *gin.Context) {
+	var req InquiryAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	var assignee interface{}
+	if req.AdminID != "" {
+		var exists bool
+		err := database.DB.QueryRow(`
+			SELECT EXISTS(SELECT 1 FROM admins WHERE id::TEXT = $1 AND deleted = FALSE AND is_active = TRUE)
+		`, req.AdminID).Scan(&exists)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Admin not found or inactive"})
+			return
+		}
+		assignee = req.AdminID
+	}
+
+	res, err := database.DB.Exec(`
+		UPDATE inquiries SET assigned_to = $1, updated_at = NOW() WHERE id::TEXT = $2
+	`, assignee, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign inquiry"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inquiry not found"})
+		return
+	}
+
+	AdminGetInquiry(c)
+}
+
+// POST /api/admin/inquiries/:id/notes
+func AdminAddInquiryNote(c *gin.Context) {
+	adminID := c.MustGet("admin_id").(string)
+
+	var req InquiryNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Note is required"})
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE inquiries SET updated_at = NOW() WHERE id::TEXT = $1`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Inquiry not found"})
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO inquiry_notes (inquiry_id, admin_id, note) VALUES ($1::UUID, $2, $3)
+	`, c.Param("id"), adminID, strings.TrimSpace(req.Note))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add note"})
+		fmt.Println("Inquiry note error:", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	AdminGetInquiry(c)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/database"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// A client can ask about at most this many talents in one enquiry
+const maxInquiryTalents = 10
+
+// inquiryTalent is an approved talent an enquiry is about
+type inquiryTalent struct {
+	TalentType string `json:"talent_type"` // model, hostess, see talentTypes
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	Name       string `json:"name"`
+}
+
+// resolveInquiryTalents looks up talent IDs or usernames among approved profiles.
+// It returns the profiles found and the references that matched none.
+func resolveInquiryTalents(refs []string) ([]inquiryTalent, []string, error) {
+	var keys []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		key := strings.ToLower(strings.TrimSpace(ref))
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil, nil
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT talent_type, id::TEXT, username, first_name FROM talents
+		WHERE status = 'approved' AND deleted = FALSE AND (id::TEXT = ANY($1) OR LOWER(username) = ANY($1))
+	`, pq.Array(keys))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var talents []inquiryTalent
+	matched := map[string]bool{}
+	for rows.Next() {
+		var t inquiryTalent
+		if err := rows.Scan(&t.TalentType, &t.ID, &t.Username, &t.Name); err != nil {
+			return nil, nil, err
+		}
+		talents = append(talents, t)
+		matched[t.ID] = true
+		matched[strings.ToLower(t.Username)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var unknown []string
+	for _, key := range keys {
+		if !matched[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	return talents, unknown, nil
+}
+
+func saveInquiryTalents(tx *sql.Tx, inquiryID string, talents []inquiryTalent) error {
+	for _, t := range talents {
+		if findTalentType(t.TalentType) == nil {
+			return fmt.Errorf("inquiry talent %s has unknown talent type %q", t.ID, t.TalentType)
+		}
+		_, err := tx.Exec(`
+			INSERT INTO inquiry_talents (inquiry_id, talent_type, talent_id, username)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, inquiryID, t.TalentType, t.ID, t.Username)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadInquiryTalents returns the talents of each inquiry, keyed by inquiry id.
+// The username is the one stored with the enquiry, so renamed or deleted profiles still show.
+func loadInquiryTalents(inquiryIDs []string) (map[string][]inquiryTalent, error) {
+	byInquiry := map[string][]inquiryTalent{}
+	if len(inquiryIDs) == 0 {
+		return byInquiry, nil
+	}
+
+	rows, err := database.DB.Query(`
+		SELECT it.inquiry_id::TEXT, it.talent_type, it.talent_id::TEXT, it.username,
+		       COALESCE(t.first_name, '')
+		FROM inquiry_talents it
+		LEFT JOIN talents t ON t.id = it.talent_id
+		WHERE it.inquiry_id::TEXT = ANY($1)
+		ORDER BY it.talent_type, it.username
+	`, pq.Array(inquiryIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			inquiryID string
+			t         inquiryTalent
+		)
+		if err := rows.Scan(&inquiryID, &t.TalentType, &t.ID, &t.Username, &t.Name); err != nil {
+			return nil, err
+		}
+		byInquiry[inquiryID] = append(byInquiry[inquiryID], t)
+	}
+	return byInquiry, rows.Err()
+}
@@ -6,18 +6,15 @@ import (
 	"time"
 
 	"fmt"
-	"math/rand"
+	"models/auth"
 	"models/database"
+	"models/outbox"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 
-	"os"
 	"regexp"
 	"strings"
 
-	"github.com/mailjet/mailjet-apiv3-go"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type User  struct {
@@ -35,15 +32,12 @@ type User  struct {
 }
 
 
-// Function to validate phone number
-func isValidPhoneNumber(phonenumber string) bool {
-	// Check if the phone number has exactly 10 digits and starts with "07"
-	if len(phonenumber) != 10 || !strings.HasPrefix(phonenumber, "07") {
-		return false
-	}
-	return true
-}
-
+// Registration states stored in users.registration_status
+const (
+	RegistrationPendingVerification = "pending_verification" // code sent, email not verified yet
+	RegistrationVerifiedIncomplete  = "verified_incomplete"  // email verified, profile and password missing
+	RegistrationActive              = "active"               // registration completed
+)
 
 // Function to validate email format using regex
 func isValidEmail(email string) bool {
@@ -71,84 +65,89 @@ func StartRegistration(ctx *gin.Context) {
         return
     }
 
-    // Check if email already exists
-    var exists bool
-    err := database.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", req.Email).Scan(&exists)
-    if err != nil {
+    // Check if email already exists. Only finished accounts are refused;
+    // earlier attempts that never verified or completed get a fresh code.
+    var status string
+    var sentAt sql.NullTime
+    err := database.DB.QueryRow(
+        "SELECT registration_status, verification_sent_at FROM users WHERE email = $1", req.Email,
+    ).Scan(&status, &sentAt)
+    if err != nil && err != sql.ErrNoRows {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
         return
     }
-    if exists {
-        ctx.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
-        return
+    if err == nil {
+        if status == RegistrationActive {
+            ctx.JSON(http.StatusConflict, gin.H{"error": "Email already registered", "code": "already_registered"})
+            return
+        }
+        if wait := resendWait(sentAt); wait > 0 {
+            respondTooManyRequests(ctx, "resend_cooldown", "Please wait before requesting a new code", wait)
+            return
+        }
     }
 
     // Generate OTP
-    otp := fmt.Sprintf("%06d", rand.Intn(1000000))
-    expiry := time.Now().Add(10 * time.Minute)
-
-    // Insert new row with OTP (email only for now)
-    _, err = database.DB.Exec(
-        `INSERT INTO users (email, verification_code, verification_expiry) 
-         VALUES ($1, $2, $3) 
-         ON CONFLICT (email) DO UPDATE SET verification_code=$2, verification_expiry=$3`,
-        req.Email, otp, expiry,
+    otp, err := generateOTP()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+        return
+    }
+    expiry := time.Now().Add(otpTTL)
+
+    // Insert new row with the hashed OTP (email only for now). An incomplete
+    // account has to prove ownership of the email again before completing.
+    // The browser language becomes the account's email language.
+    // The code and its email are committed together, so a code is never saved without its email.
+    lang := requestLanguage(ctx)
+    tx, err := database.DB.Begin()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        return
+    }
+    defer tx.Rollback()
+
+    res, err := tx.Exec(
+        `INSERT INTO users (email, verification_code, verification_expiry, verification_attempts, verification_sent_at, registration_status, preferred_language) 
+         VALUES ($1, $2, $3, 0, NOW(), $4, $6) 
+         ON CONFLICT (email) DO UPDATE SET verification_code=$2, verification_expiry=$3,
+             verification_attempts=0, verification_sent_at=NOW(),
+             email_verified=FALSE, registration_status=$4, preferred_language=$6
+         WHERE users.registration_status <> $5`,
+        req.Email, hashOTP(req.Email, otp), expiry, RegistrationPendingVerification, RegistrationActive, lang,
     )
     if err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OTP"})
         return
     }
+    if n, _ := res.RowsAffected(); n == 0 {
+        // Completed between the check above and the insert
+        ctx.JSON(http.StatusConflict, gin.H{"error": "Email already registered", "code": "already_registered"})
+        return
+    }
 
-    // Send OTP to email
-    err = sendVerificationEmail(req.Email, otp)
-    if err != nil {
+    // Queue the OTP email
+    if err := sendVerificationEmail(tx, req.Email, lang, otp); err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send OTP"})
+        fmt.Println("Verification email error:", err)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OTP"})
         return
     }
 
-    ctx.JSON(http.StatusOK, gin.H{"message": "Verification code sent"})
+    ctx.JSON(http.StatusOK, gin.H{
+        "message":             "Verification code sent",
+        "registration_status": RegistrationPendingVerification,
+    })
 }
 
-func sendVerificationEmail(Email, otp string) error {
-	// NEW: Mailjet implementation
-	apiKey := os.Getenv("MAILJET_API_KEY")
-	apiSecret := os.Getenv("MAILJET_API_SECRET")
-	senderEmail := os.Getenv("MAILJET_SENDER_EMAIL")
-	senderName := os.Getenv("MAILJET_SENDER_NAME")
-
-	if apiKey == "" || apiSecret == "" || senderEmail == "" {
-		return fmt.Errorf("Mailjet credentials not configured")
-	}
-
-	mailjetClient := mailjet.NewMailjetClient(apiKey, apiSecret)
-
-	subject := "One Time Password (OTP) for Email Verification sign up"
-	body := fmt.Sprintf("You requested a One Time password to confirm your email and sign up. Use the code below to to continue with your registration:\n\n%s", otp)
-
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: senderEmail,
-				Name:  senderName,
-			},
-			To: &mailjet.RecipientsV31{
-				{
-					Email: Email,
-				},
-			},
-			Subject:  subject,
-			TextPart: body,
-		},
-	}
-
-	messages := mailjet.MessagesV31{Info: messagesInfo}
-	_, err := mailjetClient.SendMailV31(&messages)
-	if err != nil {
-		return fmt.Errorf("Unable to send email via Mailjet: %v", err)
-	}
-
-	fmt.Println("Email sent successfully via Mailjet!")
-	return nil
+func sendVerificationEmail(q outbox.Execer, email, lang, otp string) error {
+	return queueCodeEmail(q, "verification_code:"+hashOTP(email, otp), email, lang, "verification_code", map[string]interface{}{
+		"Code":    otp,
+		"Minutes": int(otpTTL.Minutes()),
+	}, time.Now().Add(otpTTL))
 }
 
 ///////////////// VERIFY OTP ///////////////
@@ -163,35 +162,91 @@ func VerifyEmail(ctx *gin.Context) {
         return
     }
 
-    var dbCode string
-    var expiry time.Time
+    req.Email = strings.ToLower(req.Email)
+
+    var (
+        status   string
+        dbCode   sql.NullString
+        expiry   sql.NullTime
+        attempts int
+        sentAt   sql.NullTime
+    )
     err := database.DB.QueryRow(
-        "SELECT verification_code, verification_expiry FROM users WHERE email = $1",
+        "SELECT registration_status, verification_sent_at FROM users WHERE email = $1",
         req.Email,
-    ).Scan(&dbCode, &expiry)
+    ).Scan(&status, &sentAt)
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Email not found", "code": "not_found"})
+        return
+    }
+
+    switch status {
+    case RegistrationActive:
+        ctx.JSON(http.StatusConflict, gin.H{"error": "Email already registered", "code": "already_registered"})
+        return
+    case RegistrationVerifiedIncomplete:
+        ctx.JSON(http.StatusOK, gin.H{
+            "message":             "Email already verified",
+            "registration_status": status,
+        })
+        return
+    }
+
+    // Count the guess before comparing, so parallel requests cannot all slip
+    // under the limit. No row means the limit was already reached.
+    err = database.DB.QueryRow(`
+        UPDATE users SET verification_attempts = verification_attempts + 1
+        WHERE email = $1 AND verification_attempts < $2
+        RETURNING verification_code, verification_expiry, verification_attempts
+    `, req.Email, maxOTPAttempts).Scan(&dbCode, &expiry, &attempts)
+    if err == sql.ErrNoRows {
+        respondTooManyRequests(ctx, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+        return
+    }
     if err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Email not found"})
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
         return
     }
 
-    if time.Now().After(expiry) {
+    if !dbCode.Valid || !expiry.Valid || time.Now().After(expiry.Time) {
         ctx.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired"})
         return
     }
 
-    if req.Code != dbCode {
-        ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+    if !otpMatches(dbCode, req.Email, req.Code) {
+        if attempts >= maxOTPAttempts {
+            // Burn the code once the limit is reached
+            database.DB.Exec("UPDATE users SET verification_code = NULL WHERE email = $1 AND verification_attempts >= $2", req.Email, maxOTPAttempts)
+            respondTooManyRequests(ctx, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+            return
+        }
+        ctx.JSON(http.StatusUnauthorized, gin.H{
+            "error":              "Invalid code",
+            "attempts_remaining": maxOTPAttempts - attempts,
+        })
         return
     }
 
-    // Mark email as verified
-    _, err = database.DB.Exec("UPDATE users SET email_verified = TRUE WHERE email = $1", req.Email)
+    // Mark email as verified and consume the code. Matching on the code makes
+    // sure two requests with the right code cannot both get through.
+    res, err := database.DB.Exec(`
+        UPDATE users SET email_verified = TRUE, verification_code = NULL, verification_attempts = 0,
+            registration_status = $2
+        WHERE email = $1 AND verification_code = $3
+    `, req.Email, RegistrationVerifiedIncomplete, dbCode.String)
     if err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
         return
     }
+    if n, _ := res.RowsAffected(); n == 0 {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired"})
+        return
+    }
 
-    ctx.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+    ctx.JSON(http.StatusOK, gin.H{
+        "message":             "Email verified successfully",
+        "registration_status": RegistrationVerifiedIncomplete,
+    })
 }
 
 
@@ -211,92 +266,95 @@ func CompleteRegistration(ctx *gin.Context) {
         return
     }
 
-    // Validate phone number (10 digits, starts with 07)
-    if !isValidPhoneNumber(req.PhoneNumber) {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid phone number"})
+    req.Email = strings.ToLower(req.Email)
+
+    // Registration can only be completed once, and only after email verification
+    var status string
+    err := database.DB.QueryRow("SELECT registration_status FROM users WHERE email = $1", req.Email).Scan(&status)
+    if err == sql.ErrNoRows {
+        ctx.JSON(http.StatusNotFound, gin.H{"error": "Email not found", "code": "not_found"})
+        return
+    } else if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        return
+    }
+    switch status {
+    case RegistrationPendingVerification:
+        ctx.JSON(http.StatusForbidden, gin.H{"error": "Email has not been verified", "code": "email_not_verified"})
+        return
+    case RegistrationActive:
+        ctx.JSON(http.StatusConflict, gin.H{"error": "Registration already completed", "code": "already_completed"})
+        return
+    }
+
+    // Validate the phone number and store it in E.164 form
+    if !normalizePhoneField(ctx, "phone_number", &req.PhoneNumber, true) {
+        return
+    }
+
+    if rejectWeakPassword(ctx, req.Password, req.Email, req.Username) {
         return
     }
 
     // Hash password
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+    hashedPassword, err := auth.HashPassword(req.Password)
     if err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
         return
     }
 
-    // Update the verified user with details
-    _, err = database.DB.Exec(
-        `UPDATE users SET fullname=$1, username=$2, phone_number=$3, password=$4, position=$5
-         WHERE email=$6 AND email_verified=TRUE`,
-        req.Fullname, req.Username, req.PhoneNumber, string(hashedPassword), req.Position, req.Email,
-    )
+    lang := userLanguage(ctx, req.Email)
+    tx, err := database.DB.Begin()
     if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        return
+    }
+    defer tx.Rollback()
+
+    // Update the verified user with details
+    var userID int
+    err = tx.QueryRow(
+        `UPDATE users SET fullname=$1, username=$2, phone_number=$3, password=$4, position=$5,
+             registration_status=$7
+         WHERE email=$6 AND email_verified=TRUE AND registration_status=$8
+         RETURNING userid`,
+        req.Fullname, req.Username, req.PhoneNumber, hashedPassword, req.Position, req.Email,
+        RegistrationActive, RegistrationVerifiedIncomplete,
+    ).Scan(&userID)
+    if err == sql.ErrNoRows {
+        ctx.JSON(http.StatusConflict, gin.H{"error": "Registration already completed", "code": "already_completed"})
+        return
+    } else if err != nil {
         ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete registration"})
         fmt.Println("failed to complete registration:", err)
         return
     }
 
-		// Send welcome email to user
-		err = sendWelcomEmail(req.Email)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Error sending welcome email"})
-			return
-		}
+    // Queue the welcome email; it is delivered in the background once the registration is committed
+    if err := sendWelcomEmail(tx, userID, req.Email, lang, req.Fullname); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete registration"})
+        fmt.Println("Welcome email error:", err)
+        return
+    }
+    if err := tx.Commit(); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete registration"})
+        fmt.Println("failed to complete registration:", err)
+        return
+    }
 
     ctx.JSON(http.StatusOK, gin.H{"message": "Registration completed successfully"})
 }
 
 
-/////////// Mailjet to send the welcome email ////////////////////
-func sendWelcomEmail(userEmail string) error {
-	apiKey := os.Getenv("MAILJET_API_KEY")
-	apiSecret := os.Getenv("MAILJET_API_SECRET")
-	senderEmail := os.Getenv("MAILJET_SENDER_EMAIL")
-	senderName := os.Getenv("MAILJET_SENDER_NAME")
-
-	if apiKey == "" || apiSecret == "" || senderEmail == "" {
-		return fmt.Errorf("mailjet credentials not configured")
-	}
-
-	mailjetClient := mailjet.NewMailjetClient(apiKey, apiSecret)
-
-	subject := "WELCOME TO YOU!"
-	body := "Welcome to Models & Hostesses! We're excited to have you join our community of figures. We look forward to serving you and providing you with an exceptional Service for your events."
-
-	messagesInfo := []mailjet.InfoMessagesV31{
-		{
-			From: &mailjet.RecipientV31{
-				Email: senderEmail,
-				Name:  senderName,
-			},
-			To: &mailjet.RecipientsV31{
-				{
-					Email: userEmail,
-				},
-			},
-			Subject:  subject,
-			TextPart: body,
-		},
-	}
-
-	messages := mailjet.MessagesV31{Info: messagesInfo}
-	_, err := mailjetClient.SendMailV31(&messages)
-	if err != nil {
-		return fmt.Errorf("Unable to send email via Mailjet: %v", err)
-	}
-
-	fmt.Println("Email sent successfully via Mailjet!")
-	return nil
+/////////// Welcome email sent once registration is complete ////////////////////
+func sendWelcomEmail(q outbox.Execer, userID int, userEmail, lang, name string) error {
+	return queueTemplateEmail(q, fmt.Sprintf("welcome:%d", userID), userEmail, lang, "welcome", map[string]interface{}{"Name": name})
 }
 
 
 
 //////// Login function with jwt and middleware for protection //////////////
 
-//var jwtSecret = []byte("your_secret_key") // 🔒 Change this later!
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
-
-
 // Struct for incoming JSON
 type LoginRequest struct {
 	Email    string `json:"email"`
@@ -317,10 +375,11 @@ func LoginFunction(c *gin.Context) {
 	err := database.DB.QueryRow(`
 		SELECT userid, email, fullname, username, password, created_at
 		FROM users 
-		WHERE email = $1
-	`, req.Email).Scan(&user.UserID, &user.Email, &user.Fullname, &user.Username, &user.Password, &user.CreatedAt)
+		WHERE email = $1 AND registration_status = $2 AND deleted = FALSE
+	`, strings.ToLower(req.Email), RegistrationActive).Scan(&user.UserID, &user.Email, &user.Fullname, &user.Username, &user.Password, &user.CreatedAt)
 
 	if err == sql.ErrNoRows {
+		recordLoginAttempt(c, userAccounts.subjectType, req.Email, LoginUnknownAccount)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	} else if err != nil {
@@ -328,28 +387,57 @@ func LoginFunction(c *gin.Context) {
 		return
 	}
 
+	// ✅ Refuse while the account is locked after repeated failures
+	if userAccounts.respondIfLocked(c, user.UserID, req.Email) {
+		return
+	}
+
 	// ✅ Compare password hash
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	ok, needsRehash, err := auth.VerifyPassword(user.Password, req.Password)
+	if err != nil {
+		fmt.Println("Password hash error:", err)
+	}
+	if !ok {
+		userAccounts.handleFailedLogin(c, user.UserID, req.Email, user.Email, "Invalid email or password")
 		return
 	}
 
-	// ✅ Generate JWT token (24-hour expiration)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.UserID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-	})
+	// ✅ Upgrade bcrypt or weaker argon2id hashes now that the plain password is known
+	if needsRehash {
+		if hashed, err := auth.HashPassword(req.Password); err == nil {
+			database.DB.Exec(`UPDATE users SET password = $1 WHERE userid = $2`, hashed, user.UserID)
+		}
+	}
+
+	if _, err := userAccounts.clearFailures(user.UserID); err != nil {
+		fmt.Println("Failed to reset login failures:", err)
+	}
+	recordLoginAttempt(c, userAccounts.subjectType, req.Email, LoginSuccess)
+
+	respondUserLogin(c, user)
+}
+
+// respondUserLogin opens a session for an authenticated user and writes the login response
+func respondUserLogin(c *gin.Context, user User) {
+	// ✅ Open a session and generate a short-lived JWT bound to it
+	sessionID, refreshToken, err := createUserSession(c, user.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		fmt.Println("Session error:", err)
+		return
+	}
 
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := signUserToken(user.UserID, user.Email, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	// ✅ Return token and user info
+	// ✅ Return tokens and user info
 	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
+		"token":         tokenString,
+		"refresh_token": refreshToken,
+		"expires_in":    int(accessTokenTTL.Seconds()),
 		"user": gin.H{
 			"id":       user.UserID,
 			"email":    user.Email,
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"fmt"
+	"models/database"
+	"models/outbox"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// After maxFailedLogins wrong passwords within failedLoginWindow the account locks.
+// Each lock lasts twice as long as the previous one, up to maxLockout, until a
+// successful login or an admin unlock resets the count.
+const (
+	maxFailedLogins   = 5
+	failedLoginWindow = 15 * time.Minute
+	baseLockout       = 5 * time.Minute
+	maxLockout        = 24 * time.Hour
+)
+
+// Outcomes stored in login_attempts.outcome
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginUnknownAccount     = "unknown_account"
+	LoginLocked             = "locked"
+	LoginInactive           = "inactive"
+)
+
+// lockableAccount names the table and key column of an account kind that can be locked
+type lockableAccount struct {
+	subjectType string
+	table       string
+	idColumn    string
+}
+
+var (
+	userAccounts  = lockableAccount{subjectType: "user", table: "users", idColumn: "userid"}
+	adminAccounts = lockableAccount{subjectType: "admin", table: "admins", idColumn: "id"}
+)
+
+// recordLoginAttempt stores one login try. identifier is the email or username that was typed.
+func recordLoginAttempt(c *gin.Context, subjectType, identifier, outcome string) {
+	_, err := database.DB.Exec(`
+		INSERT INTO login_attempts (subject_type, identifier, ip_address, user_agent, outcome)
+		VALUES ($1, $2, $3, $4, $5)
+	`, subjectType, identifier, c.ClientIP(), c.Request.UserAgent(), outcome)
+	if err != nil {
+		fmt.Println("Failed to record login attempt:", err)
+	}
+}
+
+// lockoutDuration doubles with every lock: 5m, 10m, 20m ... capped at maxLockout
+func lockoutDuration(lockouts int) time.Duration {
+	d := baseLockout
+	for i := 1; i < lockouts && d < maxLockout; i++ {
+		d *= 2
+	}
+	if d > maxLockout {
+		d = maxLockout
+	}
+	return d
+}
+
+// lockedFor returns how long an account is still locked, or zero
+func (a lockableAccount) lockedFor(id interface{}) (time.Duration, error) {
+	var lockedUntil *time.Time
+	err := database.DB.QueryRow(
+		fmt.Sprintf(`SELECT locked_until FROM %s WHERE %s = $1`, a.table, a.idColumn), id,
+	).Scan(&lockedUntil)
+	if err != nil || lockedUntil == nil {
+		return 0, err
+	}
+	return time.Until(*lockedUntil), nil
+}
+
+// registerFailure counts a wrong password. When it reaches the limit the account
+// locks and the returned time is when the lock ends.
+func (a lockableAccount) registerFailure(id interface{}) (time.Time, bool, error) {
+	var (
+		failed   int
+		lockouts int
+	)
+	err := database.DB.QueryRow(fmt.Sprintf(`
+		UPDATE %s SET
+			failed_logins = CASE WHEN last_failed_login_at > NOW() - $2::INTERVAL THEN failed_logins + 1 ELSE 1 END,
+			last_failed_login_at = NOW()
+		WHERE %s = $1
+		RETURNING failed_logins, lockout_count
+	`, a.table, a.idColumn), id, fmt.Sprintf("%d seconds", int(failedLoginWindow.Seconds()))).Scan(&failed, &lockouts)
+	if err != nil || failed < maxFailedLogins {
+		return time.Time{}, false, err
+	}
+
+	lockedUntil := time.Now().Add(lockoutDuration(lockouts + 1))
+	_, err = database.DB.Exec(fmt.Sprintf(`
+		UPDATE %s SET failed_logins = 0, lockout_count = lockout_count + 1, locked_until = $2
+		WHERE %s = $1
+	`, a.table, a.idColumn), id, lockedUntil)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return lockedUntil, true, nil
+}
+
+// clearFailures resets the counters after a successful login or a manual unlock.
+// It reports whether the account exists.
+func (a lockableAccount) clearFailures(id interface{}) (bool, error) {
+	res, err := database.DB.Exec(fmt.Sprintf(`
+		UPDATE %s SET failed_logins = 0, lockout_count = 0, locked_until = NULL
+		WHERE %s = $1
+	`, a.table, a.idColumn), id)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// handleFailedLogin records a wrong password, locks the account when needed and
+// writes the response. The owner is told by email when the account locks.
+func (a lockableAccount) handleFailedLogin(c *gin.Context, id interface{}, identifier, email, invalidMessage string) {
+	recordLoginAttempt(c, a.subjectType, identifier, LoginInvalidCredentials)
+
+	lockedUntil, locked, err := a.registerFailure(id)
+	if err != nil {
+		fmt.Println("Failed to count login failure:", err)
+	}
+	if locked {
+		lang := requestLanguage(c)
+		if a == userAccounts {
+			lang = userLanguage(c, email)
+		}
+		key := fmt.Sprintf("account_locked:%s:%v:%d", a.subjectType, id, lockedUntil.Unix())
+		if err := sendAccountLockedEmail(database.DB, key, email, lang, lockedUntil); err != nil {
+			fmt.Println("Failed to queue account locked email:", err)
+		}
+		respondTooManyRequests(c, "account_locked", "Too many failed login attempts, the account is temporarily locked", time.Until(lockedUntil))
+		return
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": invalidMessage})
+}
+
+// respondIfLocked writes a 429 and returns true while the account is locked
+func (a lockableAccount) respondIfLocked(c *gin.Context, id interface{}, identifier string) bool {
+	wait, err := a.lockedFor(id)
+	if err != nil {
+		fmt.Println("Failed to read account lock:", err)
+		return false
+	}
+	if wait <= 0 {
+		return false
+	}
+	recordLoginAttempt(c, a.subjectType, identifier, LoginLocked)
+	respondTooManyRequests(c, "account_locked", "Too many failed login attempts, the account is temporarily locked", wait)
+	return true
+}
+
+func sendAccountLockedEmail(q outbox.Execer, key, email, lang string, lockedUntil time.Time) error {
+	return queueTemplateEmail(q, key, email, lang, "account_locked", map[string]interface{}{"Until": lockedUntil})
+}
+
+// GET /api/admin/login-attempts
+// Recent failed logins, newest first. Filters: ?type=user|admin, ?identifier=, ?hours= (default 24), ?limit= (default 100)
+func AdminListFailedLogins(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be a positive number"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return
+	}
+
+	query := `
+		SELECT id, subject_type, identifier, ip_address, user_agent, outcome, created_at
+		FROM login_attempts
+		WHERE outcome <> $1 AND created_at > NOW() - $2::INTERVAL
+	`
+	args := []interface{}{LoginSuccess, fmt.Sprintf("%d hours", hours)}
+
+	if subjectType := c.Query("type"); subjectType != "" {
+		args = append(args, subjectType)
+		query += fmt.Sprintf(" AND subject_type = $%d", len(args))
+	}
+	if identifier := c.Query("identifier"); identifier != "" {
+		args = append(args, identifier)
+		query += fmt.Sprintf(" AND LOWER(identifier) = LOWER($%d)", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := database.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch login attempts"})
+		fmt.Println("Login attempts query error:", err)
+		return
+	}
+	defer rows.Close()
+
+	attempts := []gin.H{}
+	for rows.Next() {
+		var (
+			id, subjectType, identifier, ip, userAgent, outcome string
+			createdAt                                           time.Time
+		)
+		if err := rows.Scan(&id, &subjectType, &identifier, &ip, &userAgent, &outcome, &createdAt); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		attempts = append(attempts, gin.H{
+			"id":           id,
+			"type":         subjectType,
+			"identifier":   identifier,
+			"ip_address":   ip,
+			"user_agent":   userAgent,
+			"outcome":      outcome,
+			"attempted_at": createdAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attempts": attempts, "count": len(attempts)})
+}
+
+func unlockAccount(c *gin.Context, account lockableAccount, id interface{}) {
+	found, err := account.clearFailures(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
+
+// POST /api/admin/users/:id/unlock
+func AdminUnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	unlockAccount(c, userAccounts, userID)
+}
+
+// POST /api/admin/admins/:id/unlock
+func AdminUnlockAdmin(c *gin.Context) {
+	unlockAccount(c, adminAccounts, c.Param("id"))
+}
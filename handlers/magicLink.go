@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/database"
+	"models/outbox"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Magic links are single-use and expire quickly; a new request replaces the previous link
+const magicLinkTTL = 15 * time.Minute
+
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Same answer whether or not the email is registered
+const magicLinkMessage = "If an account exists for this email, a sign-in link has been sent"
+
+// magicLinkURL builds the link emailed to the user. MAGIC_LINK_URL is the page of the
+// front end that posts the token to /login/magic/verify.
+func magicLinkURL(token string) string {
+	return linkWithToken(os.Getenv("MAGIC_LINK_URL"), token)
+}
+
+func sendMagicLinkEmail(q outbox.Execer, email, lang, token string) error {
+	return queueCodeEmail(q, "magic_link:"+hashToken(token), email, lang, "magic_link", map[string]interface{}{
+		"Link":    magicLinkURL(token),
+		"Code":    token,
+		"Minutes": int(magicLinkTTL.Minutes()),
+	}, time.Now().Add(magicLinkTTL))
+}
+
+// POST /login/magic
+func RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	token, tokenHash, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate link"})
+		return
+	}
+
+	// Only verified, active accounts that are not locked after failed logins get a link.
+	// Within the resend cooldown or the lock nothing is sent, but the answer stays the
+	// same so the endpoint does not tell which accounts exist or are locked.
+	lang := userLanguage(c, req.Email)
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE users SET magic_link_hash = $1, magic_link_expiry = $2, magic_link_sent_at = NOW()
+		WHERE email = $3 AND email_verified = TRUE AND registration_status = $4 AND deleted = FALSE
+		  AND (magic_link_sent_at IS NULL OR magic_link_sent_at < $5)
+		  AND (locked_until IS NULL OR locked_until <= NOW())
+	`, tokenHash, time.Now().Add(magicLinkTTL), req.Email, RegistrationActive, time.Now().Add(-otpResendCooldown))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		if err := sendMagicLinkEmail(tx, req.Email, lang, token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			fmt.Println("Failed to queue magic link email:", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": magicLinkMessage})
+}
+
+// POST /login/magic/verify
+// Returns the same tokens and user payload as LoginFunction.
+func VerifyMagicLink(c *gin.Context) {
+	var req MagicLinkVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token is required"})
+		return
+	}
+
+	var (
+		userID int
+		email  string
+	)
+	err := database.DB.QueryRow(`
+		SELECT userid, email FROM users WHERE magic_link_hash = $1 AND magic_link_expiry > NOW()
+	`, hashToken(req.Token)).Scan(&userID, &email)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired link"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// ✅ A link sent before the account locked is kept until the lock ends
+	if userAccounts.respondIfLocked(c, userID, email) {
+		return
+	}
+
+	// Clearing the hash in the same statement makes the link single-use
+	var user User
+	err = database.DB.QueryRow(`
+		UPDATE users SET magic_link_hash = NULL, magic_link_expiry = NULL
+		WHERE userid = $1 AND magic_link_hash = $2 AND magic_link_expiry > NOW()
+		  AND email_verified = TRUE AND registration_status = $3 AND deleted = FALSE
+		RETURNING userid, email, fullname, username, created_at
+	`, userID, hashToken(req.Token), RegistrationActive).Scan(&user.UserID, &user.Email, &user.Fullname, &user.Username, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired link"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	recordLoginAttempt(c, userAccounts.subjectType, user.Email, LoginSuccess)
+	respondUserLogin(c, user)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// How long an emailed one-time code stays valid
+	otpTTL = 10 * time.Minute
+	// Wrong guesses allowed before the code is burned
+	maxOTPAttempts = 5
+	// Minimum time between two codes sent to the same email
+	otpResendCooldown = 60 * time.Second
+)
+
+// generateOTP returns a 6-digit one-time code from a secure random source
+func generateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashOTP is what gets stored instead of the code itself. The email is mixed in
+// so the same code gives a different hash for every account.
+func hashOTP(email, code string) string {
+	return hashToken(email + ":" + code)
+}
+
+func otpMatches(storedHash sql.NullString, email, code string) bool {
+	if !storedHash.Valid || code == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(storedHash.String), []byte(hashOTP(email, code))) == 1
+}
+
+// resendWait returns how long to wait before another code may be sent
+func resendWait(sentAt sql.NullTime) time.Duration {
+	if !sentAt.Valid {
+		return 0
+	}
+	wait := time.Until(sentAt.Time.Add(otpResendCooldown))
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// respondTooManyRequests writes a 429 with a Retry-After header in whole seconds
+func respondTooManyRequests(ctx *gin.Context, code, message string, retryAfter time.Duration) {
+	seconds := int(retryAfter.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       message,
+		"code":        code,
+		"retry_after": seconds,
+	})
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bufio"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Password policy applied wherever a password is chosen: registration, reset,
+// password change and admin creation.
+//
+//	PASSWORD_MIN_LENGTH      minimum length in characters (default 10)
+//	BREACHED_PASSWORDS_FILE  file with one known-breached password per line; the check is skipped when unset
+const (
+	defaultMinPasswordLength = 10
+	maxPasswordLength        = 128
+)
+
+// PasswordRuleFailure names one rule a password broke
+type PasswordRuleFailure struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var (
+	breachedPasswordsOnce sync.Once
+	breachedPasswords     map[string]struct{}
+)
+
+func minPasswordLength() int {
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && n > 0 {
+		return n
+	}
+	return defaultMinPasswordLength
+}
+
+// loadBreachedPasswords reads the list once, on first use. Entries are compared case-insensitively.
+func loadBreachedPasswords() map[string]struct{} {
+	breachedPasswordsOnce.Do(func() {
+		breachedPasswords = map[string]struct{}{}
+
+		path := os.Getenv("BREACHED_PASSWORDS_FILE")
+		if path == "" {
+			return
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			log.Println("Breached password list not loaded:", err)
+			return
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				breachedPasswords[strings.ToLower(line)] = struct{}{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			log.Println("Breached password list only partly loaded:", err)
+		}
+		log.Printf("Loaded %d breached passwords from %s", len(breachedPasswords), path)
+	})
+	return breachedPasswords
+}
+
+// checkPassword returns every rule the password breaks, or nil when it is acceptable.
+// email and username are the account's own, and may be empty.
+func checkPassword(password, email, username string) []PasswordRuleFailure {
+	var failures []PasswordRuleFailure
+	lower := strings.ToLower(password)
+
+	if min := minPasswordLength(); len([]rune(password)) < min {
+		failures = append(failures, PasswordRuleFailure{"min_length", "Password must be at least " + strconv.Itoa(min) + " characters"})
+	}
+	if len([]rune(password)) > maxPasswordLength {
+		failures = append(failures, PasswordRuleFailure{"max_length", "Password must be at most " + strconv.Itoa(maxPasswordLength) + " characters"})
+	}
+
+	// The local part alone is what people tend to reuse, e.g. "jane.doe2024"
+	if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if strings.Contains(lower, email) || (len(local) >= 3 && strings.Contains(lower, local)) {
+			failures = append(failures, PasswordRuleFailure{"contains_email", "Password must not contain your email address"})
+		}
+	}
+	if username = strings.ToLower(strings.TrimSpace(username)); len(username) >= 3 && strings.Contains(lower, username) {
+		failures = append(failures, PasswordRuleFailure{"contains_username", "Password must not contain your username"})
+	}
+
+	if _, breached := loadBreachedPasswords()[lower]; breached {
+		failures = append(failures, PasswordRuleFailure{"breached", "Password appears in a list of leaked passwords, choose another one"})
+	}
+
+	return failures
+}
+
+// rejectWeakPassword writes a 400 listing the failed rules and returns true when the password breaks the policy
+func rejectWeakPassword(c *gin.Context, password, email, username string) bool {
+	failures := checkPassword(password, email, username)
+	if len(failures) == 0 {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":        "Password does not meet the password policy",
+		"code":         "weak_password",
+		"failed_rules": failures,
+	})
+	return true
+}
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/auth"
+	"models/database"
+	"models/outbox"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required"`
+	Code        string `json:"code" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Same answer whether or not the email is registered
+const forgotPasswordMessage = "If an account exists for this email, a reset code has been sent"
+
+func sendPasswordResetEmail(q outbox.Execer, email, lang, otp string) error {
+	return queueCodeEmail(q, "password_reset:"+hashOTP(email, otp), email, lang, "password_reset", map[string]interface{}{
+		"Code":    otp,
+		"Minutes": int(otpTTL.Minutes()),
+	}, time.Now().Add(otpTTL))
+}
+
+// POST /password/forgot
+func ForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	otp, err := generateOTP()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		return
+	}
+	expiry := time.Now().Add(otpTTL)
+
+	// Only accounts that finished registration can reset a password.
+	// Within the resend cooldown nothing is sent, but the answer stays the same.
+	lang := userLanguage(ctx, req.Email)
+	tx, err := database.DB.Begin()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE users SET reset_code = $1, reset_expiry = $2, reset_attempts = 0, reset_sent_at = NOW()
+		WHERE email = $3 AND registration_status = $5
+		  AND (reset_sent_at IS NULL OR reset_sent_at < $4)
+	`, hashOTP(req.Email, otp), expiry, req.Email, time.Now().Add(-otpResendCooldown), RegistrationActive)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		if err := sendPasswordResetEmail(tx, req.Email, lang, otp); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			fmt.Println("Failed to send password reset email:", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": forgotPasswordMessage})
+}
+
+// POST /password/reset
+func ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	var (
+		userID   int
+		username string
+		dbCode   sql.NullString
+		expiry   sql.NullTime
+		attempts int
+	)
+	// Count the guess before comparing, so parallel requests cannot all slip under
+	// the limit. No row means an unknown email or a code that is already burned.
+	err := database.DB.QueryRow(`
+		UPDATE users SET reset_attempts = reset_attempts + 1
+		WHERE email = $1 AND reset_attempts < $2
+		RETURNING userid, COALESCE(username, ''), reset_code, reset_expiry, reset_attempts
+	`, req.Email, maxOTPAttempts).Scan(&userID, &username, &dbCode, &expiry, &attempts)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err == sql.ErrNoRows || !expiry.Valid || time.Now().After(expiry.Time) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	if !otpMatches(dbCode, req.Email, req.Code) {
+		// Burn the code after too many wrong guesses; the response does not say so,
+		// otherwise it would reveal that the email is registered
+		if attempts >= maxOTPAttempts {
+			_, err = database.DB.Exec(`UPDATE users SET reset_code = NULL WHERE userid = $1 AND reset_attempts >= $2`, userID, maxOTPAttempts)
+			if err != nil {
+				fmt.Println("Failed to burn reset code:", err)
+			}
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	// The code stays valid so the user can retry with a stronger password,
+	// and the right code does not count as a wrong guess
+	if rejectWeakPassword(ctx, req.NewPassword, req.Email, username) {
+		_, err = database.DB.Exec(`UPDATE users SET reset_attempts = reset_attempts - 1 WHERE userid = $1 AND reset_code = $2`, userID, dbCode.String)
+		if err != nil {
+			fmt.Println("Failed to restore reset attempt:", err)
+		}
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// Matching on the code makes sure it is only used once
+	res, err := database.DB.Exec(`
+		UPDATE users SET password = $1, reset_code = NULL, reset_expiry = NULL, reset_attempts = 0
+		WHERE userid = $2 AND reset_code = $3
+	`, hashedPassword, userID, dbCode.String)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	// Anyone signed in with the old password is signed out
+	if err := revokeUserSessions(userID); err != nil {
+		fmt.Println("Failed to revoke sessions:", err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+// POST /api/admin/password/forgot
+func AdminForgotPassword(ctx *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	otp, err := generateOTP()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		return
+	}
+	expiry := time.Now().Add(otpTTL)
+
+	lang := requestLanguage(ctx)
+	tx, err := database.DB.Begin()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE admins SET reset_code = $1, reset_expiry = $2, reset_attempts = 0, reset_sent_at = NOW()
+		WHERE LOWER(email) = $3 AND deleted = FALSE AND is_active = TRUE
+		  AND (reset_sent_at IS NULL OR reset_sent_at < $4)
+	`, hashOTP(req.Email, otp), expiry, req.Email, time.Now().Add(-otpResendCooldown))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if n, _ := res.RowsAffected(); n > 0 {
+		if err := sendPasswordResetEmail(tx, req.Email, lang, otp); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			fmt.Println("Failed to send admin password reset email:", err)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": forgotPasswordMessage})
+}
+
+// POST /api/admin/password/reset
+func AdminResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+
+	var (
+		adminID  string
+		username string
+		dbCode   sql.NullString
+		expiry   sql.NullTime
+		attempts int
+	)
+	// Same atomic claim of an attempt as ResetPassword
+	err := database.DB.QueryRow(`
+		UPDATE admins SET reset_attempts = reset_attempts + 1
+		WHERE LOWER(email) = $1 AND deleted = FALSE AND is_active = TRUE AND reset_attempts < $2
+		RETURNING id, username, reset_code, reset_expiry, reset_attempts
+	`, req.Email, maxOTPAttempts).Scan(&adminID, &username, &dbCode, &expiry, &attempts)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err == sql.ErrNoRows || !expiry.Valid || time.Now().After(expiry.Time) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	if !otpMatches(dbCode, req.Email, req.Code) {
+		if attempts >= maxOTPAttempts {
+			_, err = database.DB.Exec(`UPDATE admins SET reset_code = NULL WHERE id = $1 AND reset_attempts >= $2`, adminID, maxOTPAttempts)
+			if err != nil {
+				fmt.Println("Failed to burn admin reset code:", err)
+			}
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	if rejectWeakPassword(ctx, req.NewPassword, req.Email, username) {
+		_, err = database.DB.Exec(`UPDATE admins SET reset_attempts = reset_attempts - 1 WHERE id = $1 AND reset_code = $2`, adminID, dbCode.String)
+		if err != nil {
+			fmt.Println("Failed to restore admin reset attempt:", err)
+		}
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	res, err := database.DB.Exec(`
+		UPDATE admins SET password_hash = $1, reset_code = NULL, reset_expiry = NULL, reset_attempts = 0,
+		    password_reset_required = FALSE, updated_at = NOW()
+		WHERE id = $2 AND reset_code = $3
+	`, hashedPassword, adminID, dbCode.String)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset code"})
+		return
+	}
+
+	if err := revokeAdminSessions(adminID); err != nil {
+		fmt.Println("Failed to revoke admin sessions:", err)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
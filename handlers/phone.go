@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"models/database"
+	"models/phone"
+	"models/sms"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// normalizePhoneField rewrites *number to E.164 in place. Numbers without a country
+// code are read in the PHONE_DEFAULT_REGION numbering plan. Empty values are left
+// alone unless required. On an invalid number it writes a 400 naming the field and returns false.
+func normalizePhoneField(ctx *gin.Context, field string, number *string, required bool) bool {
+	if *number == "" && !required {
+		return true
+	}
+
+	normalized, err := phone.Normalize(*number, "")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Invalid %s: use international format, e.g. +225 07 12 34 56 78", field),
+			"code":  "invalid_phone",
+			"field": field,
+		})
+		return false
+	}
+
+	*number = normalized
+	return true
+}
+
+func sendPhoneVerificationSMS(number, otp string) error {
+	return sms.Send(number, fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", otp, int(otpTTL.Minutes())))
+}
+
+// POST /api/account/phone/send-code
+// Texts a code to the account's phone number to prove the user owns it.
+func SendPhoneVerification(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var (
+		number   sql.NullString
+		verified bool
+		sentAt   sql.NullTime
+	)
+	err := database.DB.QueryRow(`
+		SELECT phone_number, phone_verified, phone_verification_sent_at FROM users WHERE userid = $1
+	`, userID).Scan(&number, &verified, &sentAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !number.Valid || number.String == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No phone number on the account"})
+		return
+	}
+	if verified {
+		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already verified", "code": "already_verified"})
+		return
+	}
+	if wait := resendWait(sentAt); wait > 0 {
+		respondTooManyRequests(c, "resend_cooldown", "Please wait before requesting a new code", wait)
+		return
+	}
+
+	otp, err := generateOTP()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OTP"})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE users
+		SET phone_verification_code = $1, phone_verification_expiry = $2,
+		    phone_verification_attempts = 0, phone_verification_sent_at = NOW()
+		WHERE userid = $3
+	`, hashOTP(number.String, otp), time.Now().Add(otpTTL), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save OTP"})
+		return
+	}
+
+	if err := sendPhoneVerificationSMS(number.String, otp); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send SMS"})
+		fmt.Println("SMS error:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Verification code sent by SMS"})
+}
+
+// POST /api/account/phone/verify
+func VerifyPhone(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	var (
+		number         sql.NullString
+		dbCode         sql.NullString
+		expiry, sentAt sql.NullTime
+		attempts       int
+	)
+	err := database.DB.QueryRow(`
+		SELECT phone_number, phone_verification_code, phone_verification_expiry,
+		       phone_verification_attempts, phone_verification_sent_at
+		FROM users WHERE userid = $1
+	`, userID).Scan(&number, &dbCode, &expiry, &attempts, &sentAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !number.Valid || !dbCode.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No phone verification pending"})
+		return
+	}
+	if attempts >= maxOTPAttempts {
+		respondTooManyRequests(c, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+		return
+	}
+	if !expiry.Valid || time.Now().After(expiry.Time) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Verification code expired"})
+		return
+	}
+
+	// The code is bound to the number it was sent to, so changing the number invalidates it
+	if !otpMatches(dbCode, number.String, req.Code) {
+		err = database.DB.QueryRow(`
+			UPDATE users
+			SET phone_verification_attempts = phone_verification_attempts + 1,
+			    phone_verification_code = CASE WHEN phone_verification_attempts + 1 >= $2 THEN NULL ELSE phone_verification_code END
+			WHERE userid = $1
+			RETURNING phone_verification_attempts
+		`, userID, maxOTPAttempts).Scan(&attempts)
+		if err == nil && attempts >= maxOTPAttempts {
+			respondTooManyRequests(c, "too_many_attempts", "Too many invalid attempts, request a new code", resendWait(sentAt))
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code", "attempts_remaining": maxOTPAttempts - attempts})
+		return
+	}
+
+	_, err = database.DB.Exec(`
+		UPDATE users
+		SET phone_verified = TRUE, phone_verification_code = NULL, phone_verification_expiry = NULL
+		WHERE userid = $1
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify phone number"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Phone number verified", "phone_number": number.String})
+}
+
+// NormalizeStoredPhoneNumbers rewrites phone numbers saved before E.164
+// normalization. Numbers that cannot be read are left as they are and logged.
+func NormalizeStoredPhoneNumbers() {
+	columns := []struct{ table, key, column string }{
+		{"users", "userid", "phone_number"},
+		{"talents", "id", "whatsapp"},
+		{"talents", "id", "emergency_contact_phone"},
+	}
+
+	for _, col := range columns {
+		rows, err := database.DB.Query(fmt.Sprintf(`
+			SELECT %s::TEXT, %s FROM %s WHERE %s IS NOT NULL AND %s <> '' AND %s NOT LIKE '+%%'
+		`, col.key, col.column, col.table, col.column, col.column, col.column))
+		if err != nil {
+			log.Printf("Failed to read %s.%s: %v", col.table, col.column, err)
+			continue
+		}
+
+		type pending struct{ id, number string }
+		var stale []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.id, &p.number); err == nil {
+				stale = append(stale, p)
+			}
+		}
+		rows.Close()
+
+		for _, p := range stale {
+			normalized, err := phone.Normalize(p.number, "")
+			if err != nil {
+				log.Printf("Leaving %s.%s of %s unchanged: %q is not a valid number", col.table, col.column, p.id, p.number)
+				continue
+			}
+			_, err = database.DB.Exec(fmt.Sprintf(`UPDATE %s SET %s = $1 WHERE %s::TEXT = $2`, col.table, col.column, col.key), normalized, p.id)
+			if err != nil {
+				log.Printf("Failed to normalize %s.%s of %s: %v", col.table, col.column, p.id, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"models/auth"
+	"models/database"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Access tokens are short-lived; sessions are kept alive by rotating refresh tokens.
+const (
+	accessTokenTTL       = 15 * time.Minute
+	userRefreshTokenTTL  = 30 * 24 * time.Hour
+	adminRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// newRefreshToken returns a random opaque token for the client and the hash stored in the database
+func newRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// signUserToken issues the access token for a user session
+func signUserToken(userID int, email, sessionID string) (string, error) {
+	return auth.Issue(strconv.Itoa(userID), auth.AudienceUser, accessTokenTTL, auth.Claims{
+		SessionID: sessionID,
+		Email:     email,
+	})
+}
+
+// signAdminToken issues the access token for an admin session
+func signAdminToken(adminID, username, role, sessionID string) (string, error) {
+	return auth.Issue(adminID, auth.AudienceAdmin, accessTokenTTL, auth.Claims{
+		SessionID: sessionID,
+		Username:  username,
+		Roles:     []string{role},
+	})
+}
+
+// createUserSession opens a session for a user and returns its id and refresh token
+func createUserSession(c *gin.Context, userID int) (string, string, error) {
+	return createSession(c, "user", userID, nil, userRefreshTokenTTL)
+}
+
+// createAdminSession opens a session for an admin and returns its id and refresh token
+func createAdminSession(c *gin.Context, adminID string) (string, string, error) {
+	return createSession(c, "admin", nil, adminID, adminRefreshTokenTTL)
+}
+
+// createSession records the device the login came from so users can recognise their sessions
+func createSession(c *gin.Context, subjectType string, userID, adminID interface{}, ttl time.Duration) (string, string, error) {
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	var sessionID string
+	err = database.DB.QueryRow(`
+		INSERT INTO sessions (subject_type, user_id, admin_id, refresh_token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, subjectType, userID, adminID, refreshHash, time.Now().Add(ttl), c.Request.UserAgent(), c.ClientIP()).Scan(&sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return sessionID, refreshToken, nil
+}
+
+// revokeUserSessions ends every open session of a user
+func revokeUserSessions(userID int) error {
+	_, err := database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
+
+// revokeAdminSessions ends every open session of an admin
+func revokeAdminSessions(adminID string) error {
+	_, err := database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE admin_id = $1 AND revoked_at IS NULL
+	`, adminID)
+	return err
+}
+
+// POST /auth/refresh
+// Exchanges a refresh token for a new access token and a new refresh token.
+// The presented refresh token stops working once it has been used.
+func RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
+		return
+	}
+
+	presentedHash := hashToken(req.RefreshToken)
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var (
+		sessionID, subjectType string
+		userID                 sql.NullInt64
+		adminID                sql.NullString
+		expiresAt              time.Time
+		revokedAt              sql.NullTime
+	)
+	err = tx.QueryRow(`
+		SELECT id, subject_type, user_id, admin_id, expires_at, revoked_at
+		FROM sessions
+		WHERE refresh_token_hash = $1
+		FOR UPDATE
+	`, presentedHash).Scan(&sessionID, &subjectType, &userID, &adminID, &expiresAt, &revokedAt)
+
+	if err == sql.ErrNoRows {
+		// A rotated token being replayed means it was copied; end that session and
+		// leave a trace of which account it belonged to
+		rows, rerr := database.DB.Query(`
+			UPDATE sessions SET revoked_at = NOW()
+			WHERE previous_token_hash = $1 AND revoked_at IS NULL
+			RETURNING id, subject_type, COALESCE(user_id::TEXT, admin_id::TEXT, '')
+		`, presentedHash)
+		if rerr == nil {
+			for rows.Next() {
+				var revokedID, revokedType, accountID string
+				if rows.Scan(&revokedID, &revokedType, &accountID) == nil {
+					log.Printf("Refresh token reuse detected: revoked session %s of %s %s, replayed from %s",
+						revokedID, revokedType, accountID, c.ClientIP())
+				}
+			}
+			rows.Close()
+		} else {
+			fmt.Println("Failed to revoke reused refresh token session:", rerr)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if revokedAt.Valid || time.Now().After(expiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+		return
+	}
+
+	// Build the new access token before rotating so a failure leaves the session untouched
+	var (
+		accessToken string
+		subject     gin.H
+	)
+	switch subjectType {
+	case "user":
+		// The same account state as at login: a closed or unfinished account ends its sessions
+		var email string
+		err = tx.QueryRow(`
+			SELECT email FROM users WHERE userid = $1 AND registration_status = $2 AND deleted = FALSE
+		`, userID.Int64, RegistrationActive).Scan(&email)
+		if err != nil {
+			tx.Rollback()
+			revokeUserSessions(int(userID.Int64))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account not found"})
+			return
+		}
+		accessToken, err = signUserToken(int(userID.Int64), email, sessionID)
+		subject = gin.H{"id": userID.Int64, "email": email}
+
+	case "admin":
+		var username, role string
+		var isActive, deleted bool
+		err = tx.QueryRow(`
+			SELECT username, role, is_active, deleted FROM admins WHERE id = $1
+		`, adminID.String).Scan(&username, &role, &isActive, &deleted)
+		if err != nil || !isActive || deleted {
+			tx.Rollback()
+			revokeAdminSessions(adminID.String)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin account is deactivated"})
+			return
+		}
+		accessToken, err = signAdminToken(adminID.String, username, role, sessionID)
+		subject = gin.H{"id": adminID.String, "username": username, "role": role}
+	}
+
+	if err != nil || accessToken == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sessions
+		SET refresh_token_hash = $1, previous_token_hash = $2, last_used_at = NOW(), ip_address = $4
+		WHERE id = $3
+	`, newHash, presentedHash, sessionID, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate refresh token"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         accessToken,
+		"refresh_token": newToken,
+		"expires_in":    int(accessTokenTTL.Seconds()),
+		subjectType:     subject,
+	})
+}
+
+// POST /auth/logout
+// Revokes the session the refresh token belongs to. Always succeeds so it can be retried safely.
+func Logout(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
+		return
+	}
+
+	_, err := database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL
+	`, hashToken(req.RefreshToken))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GET /api/account/sessions
+// Lists the user's open sessions, most recently used first. The one making the request is marked current.
+func ListAccountSessions(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+	currentID := c.GetString("session_id")
+
+	rows, err := database.DB.Query(`
+		SELECT id, user_agent, ip_address, created_at, last_used_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	defer rows.Close()
+
+	sessions := []gin.H{}
+	for rows.Next() {
+		var (
+			id, userAgent, ip              string
+			createdAt, lastUsed, expiresAt time.Time
+		)
+		if err := rows.Scan(&id, &userAgent, &ip, &createdAt, &lastUsed, &expiresAt); err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		sessions = append(sessions, gin.H{
+			"id":           id,
+			"user_agent":   userAgent,
+			"ip_address":   ip,
+			"created_at":   createdAt,
+			"last_seen_at": lastUsed,
+			"expires_at":   expiresAt,
+			"current":      id == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions, "count": len(sessions)})
+}
+
+// DELETE /api/account/sessions/:id
+// Signs out one session, e.g. on a lost phone. Its access token stops working immediately.
+func RevokeAccountSession(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	res, err := database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id::TEXT = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, c.Param("id"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session ended"})
+}
+
+// DELETE /api/account/sessions
+// Signs out everywhere, including this session. ?keep_current=true keeps the calling session open.
+func RevokeAllAccountSessions(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	keepID := ""
+	if c.Query("keep_current") == "true" {
+		keepID = c.GetString("session_id")
+	}
+
+	res, err := database.DB.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id::TEXT <> $2 AND revoked_at IS NULL
+	`, userID, keepID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end sessions"})
+		return
+	}
+	n, _ := res.RowsAffected()
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out everywhere", "sessions_ended": n})
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/database"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Setting keys and their defaults. Only keys listed here can be changed through the API.
+const (
+	SettingRequireAdmin2FA  = "require_admin_2fa"
+	SettingContactAutoReply = "contact_auto_reply" // acknowledge contact form messages to the sender
+)
+
+var settingDefaults = map[string]string{
+	SettingRequireAdmin2FA:  "false",
+	SettingContactAutoReply: "true",
+}
+
+// getSetting returns the stored value of a setting or its default
+func getSetting(key string) (string, error) {
+	var value string
+	err := database.DB.QueryRow(`SELECT value FROM settings WHERE key = $1`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return settingDefaults[key], nil
+	}
+	return value, err
+}
+
+// getBoolSetting reads a true/false setting; unreadable values count as the default
+func getBoolSetting(key string) bool {
+	value, err := getSetting(key)
+	if err != nil {
+		fmt.Println("Failed to read setting", key+":", err)
+		value = settingDefaults[key]
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		b, _ = strconv.ParseBool(settingDefaults[key])
+	}
+	return b
+}
+
+func setSetting(key, value string) error {
+	_, err := database.DB.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO UPDATE SET value = $2, updated_at = NOW()
+	`, key, value)
+	return err
+}
+
+// GET /api/admin/settings
+func AdminGetSettings(c *gin.Context) {
+	settings := gin.H{}
+	for key := range settingDefaults {
+		value, err := getSetting(key)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
+			return
+		}
+		settings[key] = value
+	}
+
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
+
+// PUT /api/admin/settings
+// Body is a flat object of setting keys to string values, e.g. {"require_admin_2fa": "true"}
+func AdminUpdateSettings(c *gin.Context) {
+	var req map[string]string
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	for key, value := range req {
+		def, ok := settingDefaults[key]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown setting: %s", key)})
+			return
+		}
+		if _, err := strconv.ParseBool(def); err == nil {
+			if _, err := strconv.ParseBool(value); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Setting %s must be true or false", key)})
+				return
+			}
+		}
+	}
+
+	for key, value := range req {
+		if err := setSetting(key, value); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+			return
+		}
+	}
+
+	AdminGetSettings(c)
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"models/database"
+	"models/mailer"
+	"models/outbox"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Statuses the talent is told about, each with its own template talent_<status>
+var notifiedTalentStatuses = []string{"under_review", "approved", "rejected"}
+
+type TalentNotificationPreferences struct {
+	UnderReview *bool `json:"under_review"`
+	Approved    *bool `json:"approved"`
+	Rejected    *bool `json:"rejected"`
+}
+
+// notifyStatusChange queues the status email for a talent unless they opted out of it.
+// Each status has a notify_<status> column on talents so the talent can turn that email off.
+// changedAt is the updated_at written with the status, so each change is queued once.
+func (t *talentType) notifyStatusChange(q outbox.Execer, talentID, status, notes string, changedAt time.Time) error {
+	if !slices.Contains(notifiedTalentStatuses, status) {
+		return nil
+	}
+
+	var (
+		email, name, lang string
+		wanted            bool
+	)
+	err := database.DB.QueryRow(fmt.Sprintf(`
+		SELECT t.email, t.first_name, COALESCE(u.preferred_language, ''), t.notify_%s
+		FROM talents t
+		LEFT JOIN users u ON u.userid = t.user_id
+		WHERE t.id::TEXT = $1
+	`, status), talentID).Scan(&email, &name, &lang, &wanted)
+	if err != nil {
+		return err
+	}
+	if !wanted {
+		return nil
+	}
+	if !mailer.IsSupportedLanguage(lang) {
+		lang = mailer.DefaultLanguage()
+	}
+
+	key := fmt.Sprintf("talent_%s:%s:%s:%d", status, t.name, talentID, changedAt.UnixNano())
+	return queueTemplateEmail(q, key, email, lang, "talent_"+status, map[string]interface{}{
+		"Name":       name,
+		"TalentType": t.name,
+		"Notes":      notes,
+	})
+}
+
+// GET /api/talents/:type/notifications, and /api/models/notifications, /api/hostesses/notifications
+func (t *talentType) getNotificationPreferences(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var underReview, approved, rejected bool
+	err := database.DB.QueryRow(`
+		SELECT notify_under_review, notify_approved, notify_rejected
+		FROM talents WHERE user_id = $1 AND talent_type = $2 AND deleted = FALSE
+	`, userID, t.name).Scan(&underReview, &approved, &rejected)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": t.label + " profile not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": TalentNotificationPreferences{
+		UnderReview: &underReview,
+		Approved:    &approved,
+		Rejected:    &rejected,
+	}})
+}
+
+// PUT /api/talents/:type/notifications, and /api/models/notifications, /api/hostesses/notifications
+// Fields left out are unchanged.
+func (t *talentType) updateNotificationPreferences(c *gin.Context) {
+	userID := c.MustGet("user_id").(int)
+
+	var req TalentNotificationPreferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	res, err := database.DB.Exec(`
+		UPDATE talents SET
+			notify_under_review = COALESCE($3, notify_under_review),
+			notify_approved = COALESCE($4, notify_approved),
+			notify_rejected = COALESCE($5, notify_rejected)
+		WHERE user_id = $1 AND talent_type = $2 AND deleted = FALSE
+	`, userID, t.name, req.UnderReview, req.Approved, req.Rejected)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		fmt.Println("Notification preferences error:", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": t.label + " profile not found"})
+		return
+	}
+
+	t.getNotificationPreferences(c)
+}
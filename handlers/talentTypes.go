@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"models/database"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// talentType is one category of talent. All categories share the talents,
+// talent_documents and talent_identity_checks tables; what only one category records
+// lives in its own attributes table. Models and hostesses are built in; further
+// categories are read from TALENT_TYPES_FILE by LoadTalentTypes, which also creates
+// their attributes tables, and are served on the /api/talents/:type routes.
+type talentType struct {
+	name   string // stored in talents.talent_type, used in /api/talents/:type and the status emails
+	label  string // Model, Hostess
+	plural string // models, hostesses: key of the list responses and upload folder
+
+	// Form field and response key of the talent id on the legacy /api/<plural> routes
+	idField string
+
+	// Whether step 1 asks for an emergency contact
+	emergencyContact bool
+
+	// Whether uploading the selfie (step 4) puts a pending profile under review
+	reviewOnSubmit bool
+
+	// Upload folder of the photos, documents and identity files, by kind;
+	// uploads/<plural>/<kind> when not set
+	uploadDirs map[string]string
+
+	attributes talentAttributes
+}
+
+// talentAttributes is the step 2 table of a category, keyed by talent_id.
+// Every attributes table also has a photo column for the gallery photos.
+type talentAttributes struct {
+	table     string
+	key       string // JSON key of the attributes in responses and admin updates
+	minPhotos int
+	fields    []talentField
+}
+
+type talentField struct {
+	name     string // column, form field and JSON key
+	kind     talentFieldKind
+	required bool
+}
+
+type talentFieldKind int
+
+const (
+	textField   talentFieldKind = iota
+	numberField                 // INT column
+	listField                   // TEXT[] column, sent as a comma-separated form value
+)
+
+var socialFields = []talentField{
+	{name: "social_instagram"},
+	{name: "social_facebook"},
+	{name: "social_twitter"},
+	{name: "social_linkedin"},
+}
+
+var (
+	modelTalents = &talentType{
+		name:    "model",
+		label:   "Model",
+		plural:  "models",
+		idField: "model_id",
+		uploadDirs: map[string]string{
+			"photos":    "uploads/measurements",
+			"documents": "uploads",
+			"identity":  "uploads/identity_check",
+		},
+		attributes: talentAttributes{
+			table:     "model_measurements",
+			key:       "measurements",
+			minPhotos: 5,
+			fields: append([]talentField{
+				{name: "experience", required: true},
+				{name: "height", kind: numberField, required: true},
+				{name: "weight", kind: numberField, required: true},
+				{name: "hips", kind: numberField},
+				{name: "waist", kind: numberField},
+				{name: "hair_color"},
+				{name: "eye_color"},
+			}, socialFields...),
+		},
+	}
+
+	hostessTalents = &talentType{
+		name:             "hostess",
+		label:            "Hostess",
+		plural:           "hostesses",
+		idField:          "hostess_id",
+		emergencyContact: true,
+		reviewOnSubmit:   true,
+		uploadDirs: map[string]string{
+			"photos":    "uploads/hostesses",
+			"documents": "uploads/hostesses/documents",
+			"identity":  "uploads/hostesses/identity",
+		},
+		attributes: talentAttributes{
+			table:     "hostess_experience",
+			key:       "experience",
+			minPhotos: 5,
+			fields: append([]talentField{
+				{name: "work_experience"},
+				{name: "languages", kind: listField},
+				{name: "skills", kind: listField},
+				{name: "availability"},
+				{name: "preferred_events", kind: listField},
+				{name: "previous_hostess_work"},
+				{name: "reference_contact"},
+				{name: "height"},
+				{name: "weight"},
+				{name: "hair_color"},
+				{name: "eye_color"},
+			}, socialFields...),
+		},
+	}
+)
+
+var talentTypes = []*talentType{modelTalents, hostessTalents}
+
+func findTalentType(name string) *talentType {
+	for _, t := range talentTypes {
+		if t.name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func talentTypeNames() []string {
+	names := make([]string, len(talentTypes))
+	for i, t := range talentTypes {
+		names[i] = t.name
+	}
+	return names
+}
+
+// talentTypeConfig is one entry of the TALENT_TYPES_FILE JSON list, e.g.
+//
+//	{"name": "promoter", "label": "Promoter", "plural": "promoters", "review_on_submit": true,
+//	 "min_photos": 3, "fields": [{"name": "languages", "kind": "list"}, {"name": "height", "kind": "number", "required": true}]}
+//
+// The attributes go to the <name>_attributes table, under "attributes" in responses.
+type talentTypeConfig struct {
+	Name             string `json:"name"`
+	Label            string `json:"label"`
+	Plural           string `json:"plural"`
+	EmergencyContact bool   `json:"emergency_contact"`
+	ReviewOnSubmit   bool   `json:"review_on_submit"`
+	MinPhotos        int    `json:"min_photos"`
+	Fields           []struct {
+		Name     string `json:"name"`
+		Kind     string `json:"kind"` // text (default), number or list
+		Required bool   `json:"required"`
+	} `json:"fields"`
+}
+
+var (
+	// Names end up in table and column names, so only plain identifiers are accepted
+	talentIdentifier = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+	talentFieldKinds = map[string]talentFieldKind{"": textField, "text": textField, "number": numberField, "list": listField}
+
+	// Columns every attributes table already has
+	reservedTalentFields = []string{"id", "talent_id", "photo", "created_at", "updated_at"}
+)
+
+// LoadTalentTypes adds the categories of TALENT_TYPES_FILE, if set, to the built-in ones
+// and creates or extends their attributes tables. It runs after the schema is in place.
+func LoadTalentTypes() error {
+	path := os.Getenv("TALENT_TYPES_FILE")
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	types, err := parseTalentTypes(data, talentTypes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, t := range types {
+		for _, query := range t.attributes.tableQueries() {
+			if _, err := database.DB.Exec(query); err != nil {
+				return fmt.Errorf("attributes table of %s: %w", t.name, err)
+			}
+		}
+	}
+	talentTypes = append(talentTypes, types...)
+	return nil
+}
+
+// parseTalentTypes reads a TALENT_TYPES_FILE; existing are the categories it may not redefine
+func parseTalentTypes(data []byte, existing []*talentType) ([]*talentType, error) {
+	var configs []talentTypeConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+
+	taken := map[string]bool{}
+	for _, t := range existing {
+		taken[t.name], taken[t.plural] = true, true
+	}
+
+	var types []*talentType
+	for _, c := range configs {
+		if !talentIdentifier.MatchString(c.Name) || !talentIdentifier.MatchString(c.Plural) {
+			return nil, fmt.Errorf("talent type %q: name and plural must be lowercase letters, digits and _", c.Name)
+		}
+		if taken[c.Name] || taken[c.Plural] {
+			return nil, fmt.Errorf("talent type %q is already defined", c.Name)
+		}
+		taken[c.Name], taken[c.Plural] = true, true
+		if c.Label == "" {
+			return nil, fmt.Errorf("talent type %q has no label", c.Name)
+		}
+
+		t := &talentType{
+			name:             c.Name,
+			label:            c.Label,
+			plural:           c.Plural,
+			idField:          "talent_id",
+			emergencyContact: c.EmergencyContact,
+			reviewOnSubmit:   c.ReviewOnSubmit,
+			attributes: talentAttributes{
+				table:     c.Name + "_attributes",
+				key:       "attributes",
+				minPhotos: c.MinPhotos,
+			},
+		}
+
+		seen := map[string]bool{}
+		for _, f := range c.Fields {
+			kind, ok := talentFieldKinds[f.Kind]
+			if !ok {
+				return nil, fmt.Errorf("talent type %q: field %q has unknown kind %q", c.Name, f.Name, f.Kind)
+			}
+			if !talentIdentifier.MatchString(f.Name) || seen[f.Name] || slices.Contains(reservedTalentFields, f.Name) {
+				return nil, fmt.Errorf("talent type %q: invalid or repeated field %q", c.Name, f.Name)
+			}
+			seen[f.Name] = true
+			t.attributes.fields = append(t.attributes.fields, talentField{name: f.Name, kind: kind, required: f.Required})
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+var talentColumnTypes = map[talentFieldKind]string{textField: "TEXT", numberField: "INT", listField: "TEXT[]"}
+
+// tableQueries creates the attributes table of a configured category and adds the columns
+// of fields added to the configuration since. Required fields are checked by the handlers,
+// so that a field can be added to a table that already has rows.
+func (a talentAttributes) tableQueries() []string {
+	queries := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    talent_id UUID NOT NULL REFERENCES talents(id) ON DELETE CASCADE,
+    photo TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);`, a.table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_talent_id ON %s(talent_id);`, a.table, a.table),
+	}
+	if len(a.fields) > 0 {
+		columns := make([]string, len(a.fields))
+		for i, f := range a.fields {
+			columns[i] = fmt.Sprintf("ADD COLUMN IF NOT EXISTS %s %s", f.name, talentColumnTypes[f.kind])
+		}
+		queries = append(queries, fmt.Sprintf(`ALTER TABLE %s %s;`, a.table, strings.Join(columns, ", ")))
+	}
+	return queries
+}
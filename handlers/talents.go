@@ -0,0 +1,1075 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"models/database"
+	"models/outbox"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/lib/pq"
+)
+
+var talentStatuses = []string{"pending", "under_review", "approved", "rejected"}
+
+// TalentProfileRequest is step 1 of the registration and the profile part of an update.
+// The emergency contact is ignored for categories that do not ask for it.
+type TalentProfileRequest struct {
+	FirstName        string `json:"first_name"`
+	LastName         string `json:"last_name"`
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	Whatsapp         string `json:"whatsapp"`
+	DateOfBirth      string `json:"date_of_birth"`
+	Gender           string `json:"gender"`
+	Nationality      string `json:"nationality"`
+	Street           string `json:"street"`
+	City             string `json:"city"`
+	ResidenceCountry string `json:"residence_country"`
+	EmergencyName    string `json:"emergency_contact_name"`
+	EmergencyRel     string `json:"emergency_contact_relationship"`
+	EmergencyPhone   string `json:"emergency_contact_phone"`
+}
+
+type TalentDocumentsRequest struct {
+	DocumentIssuerCountry string `json:"document_issuer_country"`
+	DocumentType          string `json:"document_type"`
+}
+
+// byTalentType serves a talent handler on /api/talents/:type for every category in talentTypes
+func byTalentType(handle func(*talentType, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t := findTalentType(c.Param("type"))
+		if t == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown talent type", "talent_types": talentTypeNames()})
+			return
+		}
+		handle(t, c)
+	}
+}
+
+var (
+	CreateTalent                        = byTalentType((*talentType).create)
+	AddTalentAttributes                 = byTalentType((*talentType).addAttributes)
+	AddTalentDocuments                  = byTalentType((*talentType).addDocuments)
+	UploadTalentIdentityCheck           = byTalentType((*talentType).uploadIdentityCheck)
+	GetTalentProgress                   = byTalentType((*talentType).progress)
+	UpdateTalent                        = byTalentType((*talentType).update)
+	DeleteTalent                        = byTalentType((*talentType).delete)
+	GetTalentNotificationPreferences    = byTalentType((*talentType).getNotificationPreferences)
+	UpdateTalentNotificationPreferences = byTalentType((*talentType).updateNotificationPreferences)
+	GetApprovedTalents                  = byTalentType((*talentType).listApproved)
+
+	AdminGetAllTalents = byTalentType((*talentType).adminList)
+	AdminGetTalentById = byTalentType((*talentType).adminGet)
+	AdminUpdateTalent  = byTalentType((*talentType).adminUpdate)
+	AdminDeleteTalent  = byTalentType((*talentType).adminDelete)
+	AdminApproveTalent = byTalentType(func(t *talentType, c *gin.Context) { t.updateStatus(c, "approved") })
+	AdminReviewTalent  = byTalentType(func(t *talentType, c *gin.Context) { t.updateStatus(c, "under_review") })
+	AdminRejectTalent  = byTalentType(func(t *talentType, c *gin.Context) { t.updateStatus(c, "rejected") })
+)
+
+// noun is the label for use inside a sentence, e.g. "model"
+func (t *talentType) noun() string {
+	return strings.ToLower(t.label)
+}
+
+func (t *talentType) uploadDir(kind string) string {
+	if dir, ok := t.uploadDirs[kind]; ok {
+		return dir
+	}
+	return filepath.Join("uploads", t.plural, kind)
+}
+
+// formTalentID reads the talent id of a registration step, sent as talent_id or,
+// on the legacy routes, as model_id / hostess_id
+func (t *talentType) formTalentID(ctx *gin.Context) string {
+	if id := ctx.PostForm("talent_id"); id != "" {
+		return id
+	}
+	return ctx.PostForm(t.idField)
+}
+
+// findTalent checks that talentID is a profile of this category
+func (t *talentType) findTalent(ctx *gin.Context, talentID string) (ownerID int, ok bool) {
+	if talentID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": t.label + " ID is required"})
+		return 0, false
+	}
+
+	err := database.DB.QueryRow(`
+		SELECT user_id FROM talents WHERE id::TEXT = $1 AND talent_type = $2 AND deleted = FALSE
+	`, talentID, t.name).Scan(&ownerID)
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": t.label + " not found"})
+		return 0, false
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return 0, false
+	}
+	return ownerID, true
+}
+
+// ownTalent checks that talentID is a profile of this category owned by the signed-in user
+func (t *talentType) ownTalent(ctx *gin.Context, talentID string) bool {
+	ownerID, ok := t.findTalent(ctx, talentID)
+	if !ok {
+		return false
+	}
+	if ownerID != ctx.MustGet("user_id").(int) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own " + t.noun() + " profile"})
+		return false
+	}
+	return true
+}
+
+func setRegistrationStep(talentID string, step int) {
+	_, _ = database.DB.Exec(`UPDATE talents SET registration_step = $2 WHERE id::TEXT = $1`, talentID, step)
+}
+
+// normalizePhones writes the phone numbers in E.164 form and drops the emergency
+// contact of categories that do not ask for one
+func (t *talentType) normalizePhones(ctx *gin.Context, req *TalentProfileRequest, required bool) bool {
+	if !normalizePhoneField(ctx, "whatsapp", &req.Whatsapp, required) {
+		return false
+	}
+	if !t.emergencyContact {
+		req.EmergencyName, req.EmergencyRel, req.EmergencyPhone = "", "", ""
+		return true
+	}
+	return normalizePhoneField(ctx, "emergency_contact_phone", &req.EmergencyPhone, false)
+}
+
+// parseDateOfBirth returns nil for an empty date so that updates keep the stored one
+func parseDateOfBirth(ctx *gin.Context, value string) (interface{}, bool) {
+	if value == "" {
+		return nil, true
+	}
+	dob, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+		return nil, false
+	}
+	return dob, true
+}
+
+// PERSONAL INFO (Step 1 of registration)
+// The profile is linked to the signed-in user and takes the account email.
+func (t *talentType) create(ctx *gin.Context) {
+	var req TalentProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		fmt.Println("Invalid input:", err)
+		return
+	}
+
+	userID := ctx.MustGet("user_id").(int)
+	email := ctx.MustGet("email").(string)
+
+	if !t.normalizePhones(ctx, &req, true) {
+		return
+	}
+	if req.DateOfBirth == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Date of birth is required"})
+		return
+	}
+	dob, ok := parseDateOfBirth(ctx, req.DateOfBirth)
+	if !ok {
+		return
+	}
+
+	var talentID string
+	err := database.DB.QueryRow(`
+		INSERT INTO talents (
+			talent_type, user_id, first_name, last_name, username, email, whatsapp, date_of_birth,
+			gender, nationality, street, city, residence_country,
+			emergency_contact_name, emergency_contact_relationship, emergency_contact_phone
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,NULLIF($14,''),NULLIF($15,''),NULLIF($16,''))
+		RETURNING id
+	`, t.name, userID, req.FirstName, req.LastName, req.Username, email, req.Whatsapp, dob,
+		req.Gender, req.Nationality, req.Street, req.City, req.ResidenceCountry,
+		req.EmergencyName, req.EmergencyRel, req.EmergencyPhone,
+	).Scan(&talentID)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "This username or email is already used by another " + t.noun() + " profile"})
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create " + t.noun()})
+		fmt.Println("Failed to create talent:", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"id":      talentID,
+		"step":    1,
+		"message": "Step 1 saved successfully",
+	})
+}
+
+// ATTRIBUTES (Step 2 of registration): the fields of the category's attributes table and the photos
+func (t *talentType) addAttributes(ctx *gin.Context) {
+	talentID := t.formTalentID(ctx)
+	if !t.ownTalent(ctx, talentID) {
+		return
+	}
+
+	columns := []string{"talent_id"}
+	values := []interface{}{talentID}
+	var missing []string
+	for _, f := range t.attributes.fields {
+		raw := strings.TrimSpace(ctx.PostForm(f.name))
+		if raw == "" && f.required {
+			missing = append(missing, f.name)
+			continue
+		}
+		value, ok := f.formValue(raw)
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": f.name + " must be a whole number"})
+			return
+		}
+		columns = append(columns, f.name)
+		values = append(values, value)
+	}
+	if len(missing) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields", "missing_fields": missing})
+		return
+	}
+
+	photos, ok := t.savePhotos(ctx)
+	if !ok {
+		return
+	}
+	columns = append(columns, "photo")
+	values = append(values, pq.Array(photos))
+
+	placeholders := make([]string, len(values))
+	for i := range values {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	_, err := database.DB.Exec(fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`,
+		t.attributes.table, strings.Join(columns, ", "), strings.Join(placeholders, ", ")), values...)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save " + t.attributes.key})
+		fmt.Println("DB insert error:", err)
+		return
+	}
+
+	setRegistrationStep(talentID, 2)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Step 2 saved successfully"})
+}
+
+// savePhotos stores the "photo" files of a multipart form and returns their paths
+func (t *talentType) savePhotos(ctx *gin.Context) ([]string, bool) {
+	var files []*multipart.FileHeader
+	if form, err := ctx.MultipartForm(); err == nil {
+		files = form.File["photo"]
+	}
+	if len(files) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No photos were detected. Photos are required"})
+		return nil, false
+	}
+	if len(files) < t.attributes.minPhotos {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Minimum %d photos are required", t.attributes.minPhotos)})
+		return nil, false
+	}
+
+	uploadDir := t.uploadDir("photos")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
+		return nil, false
+	}
+
+	var paths []string
+	for _, file := range files {
+		path := fmt.Sprintf("%s/%d_%s", uploadDir, time.Now().UnixNano(), filepath.Base(file.Filename))
+		if err := ctx.SaveUploadedFile(file, path); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save photo"})
+			return nil, false
+		}
+		paths = append(paths, path)
+	}
+	return paths, true
+}
+
+// DOCUMENTS (Step 3 of registration): front and back of an identity document
+func (t *talentType) addDocuments(ctx *gin.Context) {
+	talentID := t.formTalentID(ctx)
+	if !t.ownTalent(ctx, talentID) {
+		return
+	}
+
+	documentIssuerCountry := ctx.PostForm("documentIssuerCountry")
+	documentType := ctx.PostForm("documentType")
+	if documentIssuerCountry == "" || documentType == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Missing required fields"})
+		return
+	}
+
+	frontFile, err := ctx.FormFile("documentFront")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Front document image is required"})
+		return
+	}
+	backFile, err := ctx.FormFile("documentBack")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Back document image is required"})
+		return
+	}
+
+	uploadDir := t.uploadDir("documents")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
+		return
+	}
+
+	now := time.Now().Unix()
+	frontPath := fmt.Sprintf("%s/%s_%d_front_%s", uploadDir, talentID, now, filepath.Base(frontFile.Filename))
+	backPath := fmt.Sprintf("%s/%s_%d_back_%s", uploadDir, talentID, now, filepath.Base(backFile.Filename))
+
+	if err := ctx.SaveUploadedFile(frontFile, frontPath); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save front image"})
+		return
+	}
+	if err := ctx.SaveUploadedFile(backFile, backPath); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save back image"})
+		return
+	}
+
+	var docID string
+	err = database.DB.QueryRow(`
+		INSERT INTO talent_documents (talent_id, document_issuer_country, document_type, document_front, document_back)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, talentID, documentIssuerCountry, documentType, frontPath, backPath).Scan(&docID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save documents"})
+		fmt.Println("Database error:", err)
+		return
+	}
+
+	setRegistrationStep(talentID, 3)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"id":      docID,
+		"message": "Step 3 saved successfully",
+	})
+}
+
+// IDENTITY CHECK (Step 4 of registration): a selfie holding the document
+func (t *talentType) uploadIdentityCheck(ctx *gin.Context) {
+	talentID := t.formTalentID(ctx)
+	if !t.ownTalent(ctx, talentID) {
+		return
+	}
+
+	file, err := ctx.FormFile("selfie_with_id")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Selfie with ID file is required"})
+		return
+	}
+
+	uploadDir := t.uploadDir("identity")
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create upload directory"})
+		return
+	}
+
+	savePath := fmt.Sprintf("%s/%s_%d_%s", uploadDir, talentID, time.Now().Unix(), filepath.Base(file.Filename))
+	if err := ctx.SaveUploadedFile(file, savePath); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save selfie with ID"})
+		return
+	}
+
+	_, err = database.DB.Exec(`INSERT INTO talent_identity_checks (talent_id, selfie_with_id) VALUES ($1, $2)`, talentID, savePath)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database insert failed"})
+		return
+	}
+
+	setRegistrationStep(talentID, 4)
+
+	// The registration is complete: a pending profile goes to the review queue
+	if t.reviewOnSubmit {
+		var changedAt time.Time
+		err = database.DB.QueryRow(`
+			UPDATE talents SET status = 'under_review', updated_at = NOW()
+			WHERE id::TEXT = $1 AND status = 'pending'
+			RETURNING updated_at
+		`, talentID).Scan(&changedAt)
+		if err == nil {
+			if err := t.notifyStatusChange(database.DB, talentID, "under_review", "", changedAt); err != nil {
+				fmt.Println("Status email error:", err)
+			}
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Selfie with ID uploaded successfully",
+		"file":    savePath,
+	})
+}
+
+// User's last progress
+func (t *talentType) progress(ctx *gin.Context) {
+	userID := ctx.MustGet("user_id").(int)
+
+	var talentID string
+	var step int
+	err := database.DB.QueryRow(`
+		SELECT id, COALESCE(registration_step, 1)
+		FROM talents
+		WHERE user_id = $1 AND talent_type = $2 AND deleted = FALSE
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, userID, t.name).Scan(&talentID, &step)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No " + t.noun() + " found for user"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		t.idField:      talentID,
+		"current_step": step,
+	})
+}
+
+// Soft delete of the user's own profile
+func (t *talentType) delete(ctx *gin.Context) {
+	talentID := ctx.Param("id")
+	if !t.ownTalent(ctx, talentID) {
+		return
+	}
+
+	_, err := database.DB.Exec(`UPDATE talents SET deleted = TRUE, updated_at = NOW() WHERE id::TEXT = $1`, talentID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete " + t.noun()})
+		fmt.Println("Delete error:", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": t.label + " deleted successfully"})
+}
+
+// Update of the user's own profile: the fields are replaced by the ones sent, except
+// the email, which follows the account (see ConfirmEmailChange).
+func (t *talentType) update(ctx *gin.Context) {
+	talentID := ctx.Param("id")
+	if !t.ownTalent(ctx, talentID) {
+		return
+	}
+
+	var req TalentProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		fmt.Println("Invalid input:", err)
+		return
+	}
+	req.Email = ""
+
+	dob, ok := parseDateOfBirth(ctx, req.DateOfBirth)
+	if !ok || !t.normalizePhones(ctx, &req, false) {
+		return
+	}
+
+	if err := updateTalentProfile(database.DB, talentID, &req, dob, ""); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun()})
+		fmt.Println("Update error:", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": t.label + " updated successfully",
+		t.idField: talentID,
+	})
+}
+
+// updateTalentProfile writes the fields of req as sent, so an empty value clears the field.
+// The email, a nil dob and an empty status keep the stored value, as none of them can be blank.
+func updateTalentProfile(q outbox.Execer, talentID string, req *TalentProfileRequest, dob interface{}, status string) error {
+	_, err := q.Exec(`
+		UPDATE talents SET
+			first_name = $2,
+			last_name = $3,
+			username = $4,
+			email = COALESCE(NULLIF($5, ''), email),
+			whatsapp = $6,
+			date_of_birth = COALESCE($7, date_of_birth),
+			gender = $8,
+			nationality = $9,
+			street = $10,
+			city = $11,
+			residence_country = $12,
+			emergency_contact_name = NULLIF($13, ''),
+			emergency_contact_relationship = NULLIF($14, ''),
+			emergency_contact_phone = NULLIF($15, ''),
+			status = COALESCE(NULLIF($16, ''), status),
+			updated_at = NOW()
+		WHERE id::TEXT = $1
+	`, talentID,
+		req.FirstName, req.LastName, req.Username, req.Email, req.Whatsapp,
+		dob, req.Gender, req.Nationality, req.Street, req.City, req.ResidenceCountry,
+		req.EmergencyName, req.EmergencyRel, req.EmergencyPhone, status,
+	)
+	return err
+}
+
+// Approved profiles with full information (for the gallery)
+func (t *talentType) listApproved(ctx *gin.Context) {
+	rows, err := database.DB.Query(t.profileQuery(`AND t.status = 'approved'`)+` ORDER BY t.created_at DESC`, t.name)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch approved " + t.plural})
+		fmt.Println("Database query error:", err)
+		return
+	}
+	defer rows.Close()
+
+	profiles := []gin.H{}
+	for rows.Next() {
+		p, err := scanTalentProfile(rows)
+		if err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		profiles = append(profiles, t.profileJSON(p, true))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		t.plural: profiles,
+		"count":  len(profiles),
+	})
+}
+
+// Admin endpoint to get all profiles of the category with complete information for review
+func (t *talentType) adminList(ctx *gin.Context) {
+	status := ctx.Query("status") // pending, under_review, approved, rejected
+	page := ctx.DefaultQuery("page", "1")
+	limit := ctx.DefaultQuery("limit", "10")
+
+	if status != "" && !slices.Contains(talentStatuses, status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status", "allowed_statuses": talentStatuses})
+		return
+	}
+
+	where := ""
+	args := []interface{}{t.name}
+	if status != "" {
+		where = " AND t.status = $2"
+		args = append(args, status)
+	}
+
+	rows, err := database.DB.Query(t.profileQuery(where)+` ORDER BY t.created_at DESC`, args...)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch " + t.plural})
+		fmt.Println("Database query error:", err)
+		return
+	}
+	defer rows.Close()
+
+	profiles := []gin.H{}
+	for rows.Next() {
+		p, err := scanTalentProfile(rows)
+		if err != nil {
+			fmt.Println("Row scan error:", err)
+			continue
+		}
+		profiles = append(profiles, t.profileJSON(p, false))
+	}
+
+	var totalCount int
+	err = database.DB.QueryRow(`SELECT COUNT(*) FROM talents t WHERE t.talent_type = $1 AND t.deleted = FALSE`+where, args...).Scan(&totalCount)
+	if err != nil {
+		fmt.Println("Count query error:", err)
+		totalCount = len(profiles)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		t.plural:         profiles,
+		"total_count":    totalCount,
+		"filtered_count": len(profiles),
+		"status_filter":  status,
+		"page":           page,
+		"limit":          limit,
+	})
+}
+
+// Admin endpoint to get one profile by ID with complete information
+func (t *talentType) adminGet(ctx *gin.Context) {
+	talentID := ctx.Param("id")
+
+	p, err := scanTalentProfile(database.DB.QueryRow(t.profileQuery(` AND t.id::TEXT = $2`), t.name, talentID))
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": t.label + " not found"})
+		return
+	} else if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		fmt.Println("Talent query error:", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{t.name: t.profileJSON(p, false)})
+}
+
+// Common function for status update; the talent is emailed about the change
+func (t *talentType) updateStatus(ctx *gin.Context, newStatus string) {
+	talentID := ctx.Param("id")
+
+	var currentStatus string
+	err := database.DB.QueryRow(`
+		SELECT status FROM talents
+		WHERE id::TEXT = $1 AND talent_type = $2 AND deleted = FALSE
+	`, talentID, t.name).Scan(&currentStatus)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": t.label + " not found"})
+		return
+	}
+
+	// Approved and rejected are final
+	if currentStatus == "approved" || currentStatus == "rejected" || currentStatus == newStatus {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("%s is already %s", t.label, currentStatus),
+		})
+		return
+	}
+
+	// Admin notes are optional
+	var req struct {
+		AdminNotes string `json:"admin_notes"`
+	}
+	ctx.ShouldBindJSON(&req)
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	var changedAt time.Time
+	err = tx.QueryRow(`
+		UPDATE talents
+		SET status = $1, updated_at = NOW()
+		WHERE id::TEXT = $2
+		RETURNING updated_at
+	`, newStatus, talentID).Scan(&changedAt)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun() + " status"})
+		fmt.Println("Update error:", err)
+		return
+	}
+
+	// Tell the talent by email, with the admin notes, unless they turned this email off
+	if err := t.notifyStatusChange(tx, talentID, newStatus, req.AdminNotes, changedAt); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun() + " status"})
+		fmt.Println("Status email error:", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	logMessage := fmt.Sprintf("%s %s %s by admin", t.label, talentID, newStatus)
+	if req.AdminNotes != "" {
+		logMessage += fmt.Sprintf(" - Notes: %s", req.AdminNotes)
+	}
+	fmt.Println(logMessage)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     fmt.Sprintf("%s %s successfully", t.label, newStatus),
+		t.idField:     talentID,
+		"new_status":  newStatus,
+		"admin_notes": req.AdminNotes,
+	})
+}
+
+// Admin update of a profile: the profile fields are replaced by the ones sent. The attributes
+// go under the category key (measurements, experience), where only the fields sent change;
+// photos are not changed here.
+func (t *talentType) adminUpdate(ctx *gin.Context) {
+	talentID := ctx.Param("id")
+
+	var exists bool
+	err := database.DB.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM talents WHERE id::TEXT = $1 AND talent_type = $2 AND deleted = FALSE)
+	`, talentID, t.name).Scan(&exists)
+	if err != nil || !exists {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": t.label + " not found"})
+		return
+	}
+
+	var req struct {
+		TalentProfileRequest
+		Status    string                 `json:"status"`
+		Documents TalentDocumentsRequest `json:"documents"`
+	}
+	var sections map[string]json.RawMessage
+	if err := ctx.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	if err := ctx.ShouldBindBodyWith(&sections, binding.JSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	attributes := map[string]interface{}{}
+	if raw, ok := sections[t.attributes.key]; ok {
+		if err := json.Unmarshal(raw, &attributes); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + t.attributes.key})
+			return
+		}
+	}
+	assignments, values, badField := t.attributeAssignments(attributes)
+	if badField != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid value for " + badField})
+		return
+	}
+
+	if req.Status != "" && !slices.Contains(talentStatuses, req.Status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status", "allowed_statuses": talentStatuses})
+		return
+	}
+	dob, ok := parseDateOfBirth(ctx, req.DateOfBirth)
+	if !ok || !t.normalizePhones(ctx, &req.TalentProfileRequest, false) {
+		return
+	}
+
+	tx, err := database.DB.Begin()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := updateTalentProfile(tx, talentID, &req.TalentProfileRequest, dob, req.Status); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun() + " basic info"})
+		fmt.Println("Update error:", err)
+		return
+	}
+
+	if len(assignments) > 0 {
+		values = append(values, talentID)
+		_, err = tx.Exec(fmt.Sprintf(`UPDATE %s SET %s, updated_at = NOW() WHERE talent_id::TEXT = $%d`,
+			t.attributes.table, strings.Join(assignments, ", "), len(values)), values...)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun() + " " + t.attributes.key})
+			fmt.Println("Update error:", err)
+			return
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE talent_documents
+		SET document_issuer_country = COALESCE(NULLIF($1, ''), document_issuer_country),
+		    document_type = COALESCE(NULLIF($2, ''), document_type),
+		    updated_at = NOW()
+		WHERE talent_id::TEXT = $3
+	`, req.Documents.DocumentIssuerCountry, req.Documents.DocumentType, talentID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + t.noun() + " documents"})
+		fmt.Println("Update error:", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit changes"})
+		return
+	}
+
+	fmt.Printf("%s %s updated by admin\n", t.label, talentID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": t.label + " updated successfully",
+		t.idField: talentID,
+	})
+}
+
+// attributeAssignments turns the attributes of an admin update into "column = $n" pairs.
+// Unknown keys are ignored; badField names the first field whose value has the wrong type.
+func (t *talentType) attributeAssignments(attributes map[string]interface{}) (assignments []string, values []interface{}, badField string) {
+	for _, f := range t.attributes.fields {
+		raw, ok := attributes[f.name]
+		if !ok {
+			continue
+		}
+		value, ok := f.jsonValue(raw)
+		if !ok {
+			return nil, nil, f.name
+		}
+		values = append(values, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", f.name, len(values)))
+	}
+	return assignments, values, ""
+}
+
+// Admin soft delete of any profile
+func (t *talentType) adminDelete(ctx *gin.Context) {
+	talentID := ctx.Param("id")
+
+	res, err := database.DB.Exec(`
+		UPDATE talents SET deleted = TRUE, updated_at = NOW()
+		WHERE id::TEXT = $1 AND talent_type = $2 AND deleted = FALSE
+	`, talentID, t.name)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete " + t.noun()})
+		fmt.Println("Delete error:", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": t.label + " not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": t.label + " deleted successfully"})
+}
+
+// formValue converts a registration form value for its column; empty values are stored as NULL
+func (f talentField) formValue(raw string) (interface{}, bool) {
+	switch {
+	case f.kind == listField:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return pq.Array(items), true
+	case raw == "":
+		return nil, true
+	case f.kind == numberField:
+		n, err := strconv.Atoi(raw)
+		return n, err == nil
+	}
+	return raw, true
+}
+
+// jsonValue converts a value of an admin update body for its column
+func (f talentField) jsonValue(v interface{}) (interface{}, bool) {
+	if v == nil {
+		return nil, true
+	}
+	switch f.kind {
+	case listField:
+		list, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		items := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			items = append(items, s)
+		}
+		return pq.Array(items), true
+	case numberField:
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			return nil, false
+		}
+		return int64(n), true
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// responseValue reads a column from the attributes row, with "", 0 or [] when it is empty
+func (f talentField) responseValue(v interface{}) interface{} {
+	switch f.kind {
+	case numberField:
+		n, _ := v.(float64)
+		return int64(n)
+	case listField:
+		items := []string{}
+		list, _ := v.([]interface{})
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+		return items
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// talentProfile is a talent with its attributes, documents and identity check
+type talentProfile struct {
+	ID, UserID, FirstName, LastName, Username, Email, Whatsapp               string
+	DateOfBirth, Gender, Nationality, Street, City, ResidenceCountry, Status string
+	RegistrationStep                                                         int
+	Deleted                                                                  bool
+	CreatedAt, UpdatedAt                                                     time.Time
+	EmergencyName, EmergencyRel, EmergencyPhone                              string
+	Attributes                                                               map[string]interface{}
+	DocIssuerCountry, DocType, DocFront, DocBack                             string
+	SelfieWithID                                                             string
+	IdentityVerified                                                         bool
+	UserFullname, UserEmail, UserPhone                                       string
+	EnquiryCount                                                             int
+}
+
+const talentProfileColumns = `
+	t.id, t.user_id, t.first_name, t.last_name, t.username, t.email,
+	t.whatsapp, t.date_of_birth, t.gender, t.nationality, t.street,
+	t.city, t.residence_country, t.status, COALESCE(t.registration_step, 1), COALESCE(t.deleted, FALSE),
+	t.created_at, t.updated_at,
+	COALESCE(t.emergency_contact_name, ''), COALESCE(t.emergency_contact_relationship, ''), COALESCE(t.emergency_contact_phone, ''),
+	to_jsonb(a),
+	COALESCE(d.document_issuer_country, ''), COALESCE(d.document_type, ''), COALESCE(d.document_front, ''), COALESCE(d.document_back, ''),
+	COALESCE(ic.selfie_with_id, ''), COALESCE(ic.verified, FALSE),
+	COALESCE(u.fullname, ''), COALESCE(u.email, ''), COALESCE(u.phone_number, ''),
+	(SELECT COUNT(*) FROM inquiry_talents it WHERE it.talent_type = t.talent_type AND it.talent_id = t.id)
+`
+
+// profileQuery selects talentProfile rows of the category ($1); where adds conditions.
+// A registration step can be sent more than once, only its latest row is used.
+func (t *talentType) profileQuery(where string) string {
+	return fmt.Sprintf(`
+		SELECT %s
+		FROM talents t
+		LEFT JOIN LATERAL (
+			SELECT * FROM %s WHERE talent_id = t.id ORDER BY created_at DESC LIMIT 1
+		) a ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT * FROM talent_documents WHERE talent_id = t.id ORDER BY created_at DESC LIMIT 1
+		) d ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT * FROM talent_identity_checks WHERE talent_id = t.id ORDER BY created_at DESC LIMIT 1
+		) ic ON TRUE
+		LEFT JOIN users u ON u.userid = t.user_id
+		WHERE t.talent_type = $1 AND t.deleted = FALSE %s
+	`, talentProfileColumns, t.attributes.table, where)
+}
+
+func scanTalentProfile(row interface{ Scan(...interface{}) error }) (*talentProfile, error) {
+	var (
+		p          talentProfile
+		attributes []byte
+	)
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.FirstName, &p.LastName, &p.Username, &p.Email,
+		&p.Whatsapp, &p.DateOfBirth, &p.Gender, &p.Nationality, &p.Street,
+		&p.City, &p.ResidenceCountry, &p.Status, &p.RegistrationStep, &p.Deleted,
+		&p.CreatedAt, &p.UpdatedAt,
+		&p.EmergencyName, &p.EmergencyRel, &p.EmergencyPhone,
+		&attributes,
+		&p.DocIssuerCountry, &p.DocType, &p.DocFront, &p.DocBack,
+		&p.SelfieWithID, &p.IdentityVerified,
+		&p.UserFullname, &p.UserEmail, &p.UserPhone,
+		&p.EnquiryCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Attributes = map[string]interface{}{}
+	if attributes != nil {
+		if err := json.Unmarshal(attributes, &p.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// profileJSON is the response shape of a profile. The gallery gets the photos as a list
+// and the first one as "photo"; admins get the account details and the stored photo value.
+func (t *talentType) profileJSON(p *talentProfile, gallery bool) gin.H {
+	attributes := gin.H{}
+	for _, f := range t.attributes.fields {
+		attributes[f.name] = f.responseValue(p.Attributes[f.name])
+	}
+	storedPhotos, _ := p.Attributes["photo"].(string)
+
+	profile := gin.H{
+		"id":                p.ID,
+		"talent_type":       t.name,
+		"user_id":           p.UserID,
+		"first_name":        p.FirstName,
+		"last_name":         p.LastName,
+		"username":          p.Username,
+		"email":             p.Email,
+		"whatsapp":          p.Whatsapp,
+		"date_of_birth":     p.DateOfBirth,
+		"gender":            p.Gender,
+		"nationality":       p.Nationality,
+		"street":            p.Street,
+		"city":              p.City,
+		"residence_country": p.ResidenceCountry,
+		"status":            p.Status,
+		"registration_step": p.RegistrationStep,
+		"deleted":           p.Deleted,
+		"created_at":        p.CreatedAt,
+		"updated_at":        p.UpdatedAt,
+		t.attributes.key:    attributes,
+		"documents": gin.H{
+			"document_issuer_country": p.DocIssuerCountry,
+			"document_type":           p.DocType,
+			"document_front":          p.DocFront,
+			"document_back":           p.DocBack,
+		},
+		"identity_check": gin.H{
+			"selfie_with_id": p.SelfieWithID,
+			"verified":       p.IdentityVerified,
+		},
+	}
+	if t.emergencyContact {
+		profile["emergency_contact"] = gin.H{
+			"name":         p.EmergencyName,
+			"relationship": p.EmergencyRel,
+			"phone":        p.EmergencyPhone,
+		}
+	}
+
+	if gallery {
+		photos := photoList(storedPhotos)
+		attributes["photo"] = photos
+		profile["photo"] = "/uploads/default.jpg"
+		if len(photos) > 0 {
+			profile["photo"] = photos[0]
+		}
+	} else {
+		attributes["photo"] = storedPhotos
+		profile["enquiry_count"] = p.EnquiryCount
+		profile["user_info"] = gin.H{
+			"fullname": p.UserFullname,
+			"email":    p.UserEmail,
+			"phone":    p.UserPhone,
+		}
+	}
+	return profile
+}
+
+// photoList reads the photo column: a Postgres array literal, or for older rows
+// a comma-separated list or a single path
+func photoList(stored string) []string {
+	photos := []string{}
+	var array pq.StringArray
+	if strings.HasPrefix(stored, "{") && array.Scan([]byte(stored)) == nil {
+		for _, p := range array {
+			if p != "" {
+				photos = append(photos, p)
+			}
+		}
+		return photos
+	}
+	for _, p := range strings.Split(stored, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			photos = append(photos, p)
+		}
+	}
+	return photos
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B, SHA1 secret "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is their last 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tt := range tests {
+		got, err := totpCode(rfc6238Secret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("totpCode(T=%d) error = %v", tt.unix, err)
+		}
+		if want := tt.want[len(tt.want)-totpDigits:]; got != want {
+			t.Errorf("totpCode(T=%d) = %s, want %s", tt.unix, got, want)
+		}
+	}
+}
+
+func TestTOTPCodeSecretFormat(t *testing.T) {
+	want, _ := totpCode(rfc6238Secret, 1)
+	if got, err := totpCode(strings.ToLower(rfc6238Secret), 1); err != nil || got != want {
+		t.Errorf("lower case secret: got %q, %v, want %q", got, err, want)
+	}
+	if _, err := totpCode("not base32!", 1); err == nil {
+		t.Error("invalid secret: expected an error")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keep clear of a step boundary so the window does not move during the test
+	if left := totpPeriod - time.Now().Unix()%totpPeriod; left < 3 {
+		time.Sleep(time.Duration(left) * time.Second)
+	}
+	current := time.Now().Unix() / totpPeriod
+	codeAt := func(step int64) string {
+		code, err := totpCode(secret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", codeAt(current), 0, current, true},
+		{"previous step within skew", codeAt(current - 1), 0, current - 1, true},
+		{"next step within skew", codeAt(current + 1), 0, current + 1, true},
+		{"outside skew", codeAt(current - 3), 0, 0, false},
+		{"spaces are ignored", codeAt(current)[:3] + " " + codeAt(current)[3:], 0, current, true},
+		{"replayed step", codeAt(current), current, 0, false},
+		{"wrong length", codeAt(current)[:5], 0, 0, false},
+		{"empty", "", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := verifyTOTP(secret, tt.code, tt.lastStep)
+			if ok != tt.wantOK || (ok && step != tt.wantStep) {
+				t.Errorf("verifyTOTP(%q, %d) = %d, %v, want %d, %v", tt.code, tt.lastStep, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileMailer writes every message to its own .eml file, which any mail client can open
+type fileMailer struct {
+	dir string
+}
+
+func newFileMailer(dir string) (*fileMailer, error) {
+	if dir == "" {
+		dir = "mail_outbox"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create MAIL_FILE_DIR %s: %v", dir, err)
+	}
+	return &fileMailer{dir: dir}, nil
+}
+
+func (m *fileMailer) Send(from Address, msg Message) error {
+	body, err := buildMIME(from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000"), randomID()[:8])
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
@@ -0,0 +1,100 @@
+// Package mailer sends email through a driver chosen at startup.
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Address is an email address with an optional display name
+type Address struct {
+	Email string
+	Name  string
+}
+
+// Message is one email. Text is required; HTML is optional and sent as an alternative part.
+// ID, when set, is a stable identifier used for the Message-ID so a retried send can be recognised as a duplicate.
+type Message struct {
+	ID      string
+	To      []Address
+	ReplyTo *Address
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers messages. From is filled in by the package before Send is called.
+type Mailer interface {
+	Send(from Address, msg Message) error
+}
+
+// The driver is chosen with MAIL_DRIVER:
+//
+//	mailjet  Mailjet API (default): MAILJET_API_KEY, MAILJET_API_SECRET
+//	smtp     plain SMTP, e.g. a local MailHog: SMTP_HOST, SMTP_PORT (default 1025), optional SMTP_USERNAME / SMTP_PASSWORD
+//	file     writes each message as an .eml file into MAIL_FILE_DIR (default mail_outbox)
+//
+// The sender is MAIL_FROM_EMAIL / MAIL_FROM_NAME, falling back to MAILJET_SENDER_EMAIL / MAILJET_SENDER_NAME.
+var (
+	active Mailer
+	from   Address
+)
+
+var ErrNotInitialized = errors.New("mailer: no driver configured, call mailer.Init first")
+
+// Init selects the driver from the environment. It must run after the .env file is loaded.
+func Init() error {
+	sender := Address{
+		Email: firstEnv("MAIL_FROM_EMAIL", "MAILJET_SENDER_EMAIL"),
+		Name:  firstEnv("MAIL_FROM_NAME", "MAILJET_SENDER_NAME"),
+	}
+	if sender.Email == "" {
+		return fmt.Errorf("no sender address configured: set MAIL_FROM_EMAIL")
+	}
+
+	m, err := newMailer(strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))))
+	if err != nil {
+		return err
+	}
+	active, from = m, sender
+	return nil
+}
+
+func newMailer(driver string) (Mailer, error) {
+	switch driver {
+	case "", "mailjet":
+		return newMailjetMailer(os.Getenv("MAILJET_API_KEY"), os.Getenv("MAILJET_API_SECRET"))
+	case "smtp":
+		return newSMTPMailer(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	case "file":
+		return newFileMailer(os.Getenv("MAIL_FILE_DIR"))
+	}
+	return nil, fmt.Errorf("unknown MAIL_DRIVER %q, expected mailjet, smtp or file", driver)
+}
+
+// Send delivers a message with the configured driver
+func Send(msg Message) error {
+	if active == nil {
+		return ErrNotInitialized
+	}
+	if len(msg.To) == 0 {
+		return errors.New("mailer: message has no recipient")
+	}
+	return active.Send(from, msg)
+}
+
+// From is the configured sender address, which is also the agency inbox
+func From() Address {
+	return from
+}
+
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
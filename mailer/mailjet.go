@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"fmt"
+
+	"github.com/mailjet/mailjet-apiv3-go"
+)
+
+type mailjetMailer struct {
+	client *mailjet.Client
+}
+
+func newMailjetMailer(apiKey, apiSecret string) (*mailjetMailer, error) {
+	if apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("Mailjet credentials not configured: set MAILJET_API_KEY and MAILJET_API_SECRET, or choose another MAIL_DRIVER")
+	}
+	return &mailjetMailer{client: mailjet.NewMailjetClient(apiKey, apiSecret)}, nil
+}
+
+func (m *mailjetMailer) Send(from Address, msg Message) error {
+	to := make(mailjet.RecipientsV31, 0, len(msg.To))
+	for _, a := range msg.To {
+		to = append(to, mailjet.RecipientV31{Email: a.Email, Name: a.Name})
+	}
+
+	info := mailjet.InfoMessagesV31{
+		From:     &mailjet.RecipientV31{Email: from.Email, Name: from.Name},
+		To:       &to,
+		Subject:  msg.Subject,
+		TextPart: msg.Text,
+		HTMLPart: msg.HTML,
+		CustomID: msg.ID,
+	}
+	if msg.ReplyTo != nil {
+		info.ReplyTo = &mailjet.RecipientV31{Email: msg.ReplyTo.Email, Name: msg.ReplyTo.Name}
+	}
+
+	_, err := m.client.SendMailV31(&mailjet.MessagesV31{Info: []mailjet.InfoMessagesV31{info}})
+	if err != nil {
+		return fmt.Errorf("Unable to send email via Mailjet: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// buildMIME renders a message as RFC 5322 bytes, used by the SMTP and file drivers
+func buildMIME(from Address, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	to := make([]string, 0, len(msg.To))
+	for _, a := range msg.To {
+		to = append(to, formatAddress(a))
+	}
+
+	header := func(key, value string) { fmt.Fprintf(&buf, "%s: %s\r\n", key, value) }
+	header("From", formatAddress(from))
+	header("To", strings.Join(to, ", "))
+	if msg.ReplyTo != nil {
+		header("Reply-To", formatAddress(*msg.ReplyTo))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID(from, msg))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", `text/plain; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary := "alt-" + randomID()
+	header("Content-Type", fmt.Sprintf(`multipart/alternative; boundary="%s"`, boundary))
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=\"utf-8\"\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+func messageID(from Address, msg Message) string {
+	id := msg.ID
+	if id == "" {
+		id = randomID()
+	}
+	return fmt.Sprintf("<%s@%s>", id, domainOf(from.Email))
+}
+
+func formatAddress(a Address) string {
+	return (&mail.Address{Name: a.Name, Address: a.Email}).String()
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func domainOf(email string) string {
+	if _, domain, ok := strings.Cut(email, "@"); ok && domain != "" {
+		return domain
+	}
+	return "localhost"
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+}
+
+func newSMTPMailer(host, port, username, password string) (*smtpMailer, error) {
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER=smtp")
+	}
+	if port == "" {
+		port = "1025" // MailHog / Mailpit default
+	}
+
+	m := &smtpMailer{addr: net.JoinHostPort(host, port)}
+	// net/smtp only sends PLAIN credentials over TLS or to localhost
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+func (m *smtpMailer) Send(from Address, msg Message) error {
+	body, err := buildMIME(from, msg)
+	if err != nil {
+		return err
+	}
+
+	to := make([]string, 0, len(msg.To))
+	for _, a := range msg.To {
+		to = append(to, a.Email)
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, from.Email, to, body); err != nil {
+		return fmt.Errorf("Unable to send email via SMTP: %v", err)
+	}
+	return nil
+}
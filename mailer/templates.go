@@ -0,0 +1,164 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Every transactional email is a template file per language under templates/<lang>/<name>.tmpl
+// defining three blocks: "subject", "text" and "html". They are wrapped in the shared
+// layout.txt.tmpl / layout.html.tmpl, and <lang>/common.tmpl holds strings the layout
+// needs in each language.
+//
+// Any of these files can be replaced by putting a file with the same relative path in
+// MAIL_TEMPLATE_DIR; files are read on every send, so edits apply without a restart.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// Languages with a full set of templates. The first one is the fallback.
+var supportedLanguages = []string{"en", "fr"}
+
+// DefaultLanguage is MAIL_DEFAULT_LANGUAGE when supported, otherwise English
+func DefaultLanguage() string {
+	if lang := strings.ToLower(os.Getenv("MAIL_DEFAULT_LANGUAGE")); IsSupportedLanguage(lang) {
+		return lang
+	}
+	return supportedLanguages[0]
+}
+
+func IsSupportedLanguage(lang string) bool {
+	for _, l := range supportedLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchLanguage picks the best supported language from an Accept-Language header,
+// e.g. "fr-CA,fr;q=0.9,en;q=0.8" gives "fr". It falls back to DefaultLanguage.
+func MatchLanguage(acceptLanguage string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if IsSupportedLanguage(base) && q > bestQ {
+			best, bestQ = base, q
+		}
+	}
+	if best == "" {
+		return DefaultLanguage()
+	}
+	return best
+}
+
+// Render builds a message from a template. data is available to the template as
+// .Data; .Brand, .Year and .Lang are added for the layout. The recipient is left to the caller.
+func Render(name, lang string, data map[string]interface{}) (Message, error) {
+	if !IsSupportedLanguage(lang) {
+		lang = DefaultLanguage()
+	}
+
+	view := map[string]interface{}{
+		"Data":  data,
+		"Lang":  lang,
+		"Brand": brandName(),
+		"Year":  time.Now().Year(),
+	}
+
+	files := []string{path.Join(lang, "common.tmpl"), path.Join(lang, name+".tmpl")}
+	sources := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		src, err := readTemplate(f)
+		if err != nil {
+			return Message{}, fmt.Errorf("email template %s: %v", f, err)
+		}
+		sources = append(sources, src)
+	}
+
+	textLayout, err := readTemplate("layout.txt.tmpl")
+	if err != nil {
+		return Message{}, err
+	}
+	htmlLayout, err := readTemplate("layout.html.tmpl")
+	if err != nil {
+		return Message{}, err
+	}
+
+	textSet := texttemplate.New("layout").Funcs(texttemplate.FuncMap{"date": formatDate})
+	for _, src := range append([]string{textLayout}, sources...) {
+		if textSet, err = textSet.Parse(src); err != nil {
+			return Message{}, fmt.Errorf("email template %s/%s: %v", lang, name, err)
+		}
+	}
+	htmlSet := htmltemplate.New("layout").Funcs(htmltemplate.FuncMap{"date": formatDate})
+	for _, src := range append([]string{htmlLayout}, sources...) {
+		if htmlSet, err = htmlSet.Parse(src); err != nil {
+			return Message{}, fmt.Errorf("email template %s/%s: %v", lang, name, err)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := textSet.ExecuteTemplate(&subject, "subject", view); err != nil {
+		return Message{}, err
+	}
+	if err := textSet.ExecuteTemplate(&text, "layout", view); err != nil {
+		return Message{}, err
+	}
+	// The subject is shown in the HTML <title>, so it is passed in already rendered
+	view["Subject"] = strings.TrimSpace(subject.String())
+	if err := htmlSet.ExecuteTemplate(&html, "layout", view); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// readTemplate prefers a file in MAIL_TEMPLATE_DIR over the embedded default
+func readTemplate(name string) (string, error) {
+	if dir := os.Getenv("MAIL_TEMPLATE_DIR"); dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err == nil {
+			return string(b), nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	b, err := fs.ReadFile(embeddedTemplates, path.Join("templates", name))
+	return string(b), err
+}
+
+func brandName() string {
+	if name := os.Getenv("MAIL_BRAND_NAME"); name != "" {
+		return name
+	}
+	if from.Name != "" {
+		return from.Name
+	}
+	return "Models & Hostesses"
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 Jan 2006 15:04 MST")
+}
@@ -2,9 +2,14 @@ package main
 
 import (
 	"log"
+	"models/auth"
+	"models/captcha"
 	"models/database"
 	"models/handlers"
+	"models/mailer"
 	middlewares "models/middleware"
+	"models/outbox"
+	"models/sms"
 	"net/http"
 	"time"
 
@@ -19,6 +24,20 @@ func main() {
 	if err != nil {
 		log.Println("No .env file found")
 	}
+
+	// Signing keys are read only now that .env is loaded; refuse to start without them
+	if err := auth.Init(); err != nil {
+		log.Fatal("Auth configuration error: ", err)
+	}
+	if err := sms.Init(); err != nil {
+		log.Fatal("SMS configuration error: ", err)
+	}
+	if err := mailer.Init(); err != nil {
+		log.Fatal("Mail configuration error: ", err)
+	}
+	if err := captcha.Init(); err != nil {
+		log.Fatal("Captcha configuration error: ", err)
+	}
 	router := gin.Default() 	
 	database.ConnectDatabase()
 
@@ -36,9 +55,17 @@ func main() {
 	router.POST("/register/verify", handlers.VerifyEmail)       // Step 2: verify OTP
 	router.POST("/register/complete", handlers.CompleteRegistration) // Step 3: complete registration
 	router.POST("/login", handlers.LoginFunction)
+	router.POST("/login/magic", handlers.RequestMagicLink)         // Email a single-use sign-in link
+	router.POST("/login/magic/verify", handlers.VerifyMagicLink)   // Exchange the link token for a session
+	router.POST("/auth/refresh", handlers.RefreshToken)         // Rotate refresh token, get a new access token
+	router.POST("/auth/logout", handlers.Logout)                // Revoke the session of a refresh token
+	router.POST("/password/forgot", handlers.ForgotPassword)    // Email a password reset code
+	router.POST("/password/reset", handlers.ResetPassword)      // Set a new password with the code
+	router.GET("/contact/form-token", handlers.ContactFormToken) // Signed token for the contact form, see /contact
 	router.POST("/contact", handlers.HandleContact)             // Contact form submission
 	router.GET("api/hostesses/approved", handlers.GetApprovedHostesses)
 	router.GET("api/models/approved", handlers.GetApprovedModels)
+	router.GET("api/talents/:type/approved", handlers.GetApprovedTalents) // Any talent category, e.g. /api/talents/model/approved
 
 
 			 	
@@ -46,6 +73,16 @@ func main() {
     protected := router.Group("/api", middlewares.AuthMiddleware())
 {
     protected.GET("/account", handlers.GetAccountInfo)
+    protected.PUT("/account", handlers.UpdateAccount)
+    protected.DELETE("/account", handlers.CloseAccount)
+    protected.PUT("/account/password", handlers.ChangePassword)
+    protected.POST("/account/email", handlers.RequestEmailChange)
+    protected.POST("/account/email/verify", handlers.ConfirmEmailChange)
+    protected.POST("/account/phone/send-code", handlers.SendPhoneVerification) // SMS code to the account's number
+    protected.POST("/account/phone/verify", handlers.VerifyPhone)
+    protected.GET("/account/sessions", handlers.ListAccountSessions)
+    protected.DELETE("/account/sessions", handlers.RevokeAllAccountSessions) // sign out everywhere
+    protected.DELETE("/account/sessions/:id", handlers.RevokeAccountSession)
 
     // For Models (User operations)
     protected.POST("/models/create", handlers.CreateModel)
@@ -55,6 +92,8 @@ func main() {
     protected.GET("/models/progress", handlers.GetModelProgress)
     protected.DELETE("/models/:id", handlers.DeleteModel)  // User can only delete their own
     protected.PUT("/models/:id", handlers.UpdateModel)     // User can only update their own
+    protected.GET("/models/notifications", handlers.GetModelNotificationPreferences)    // Status emails the model receives
+    protected.PUT("/models/notifications", handlers.UpdateModelNotificationPreferences)
 
     // For Hostesses (User operations)
     protected.POST("/hostesses/create", handlers.CreateHostess)
@@ -64,34 +103,100 @@ func main() {
     protected.GET("/hostesses/progress", handlers.GetHostessProgress)
     protected.DELETE("/hostesses/:id", handlers.DeleteHostess)  // User can only delete their own
     protected.PUT("/hostesses/:id", handlers.UpdateHostess)     // User can only update their own
+    protected.GET("/hostesses/notifications", handlers.GetHostessNotificationPreferences) // Status emails the hostess receives
+    protected.PUT("/hostesses/notifications", handlers.UpdateHostessNotificationPreferences)
+
+    // Any talent category (model, hostess, ...); the routes above are the same handlers
+    protected.POST("/talents/:type/create", handlers.CreateTalent)
+    protected.POST("/talents/:type/attributes", handlers.AddTalentAttributes)
+    protected.POST("/talents/:type/documents", handlers.AddTalentDocuments)
+    protected.POST("/talents/:type/identity-check", handlers.UploadTalentIdentityCheck)
+    protected.GET("/talents/:type/progress", handlers.GetTalentProgress)
+    protected.DELETE("/talents/:type/:id", handlers.DeleteTalent)
+    protected.PUT("/talents/:type/:id", handlers.UpdateTalent)
+    protected.GET("/talents/:type/notifications", handlers.GetTalentNotificationPreferences)
+    protected.PUT("/talents/:type/notifications", handlers.UpdateTalentNotificationPreferences)
 }
 
 
 // ===== ADMIN PUBLIC ROUTES =====
-router.POST("/api/admin/register", handlers.AdminRegister)
+router.POST("/api/admin/invitations/accept", handlers.AdminAcceptInvitation) // Invite-only onboarding
 router.POST("/api/admin/login", handlers.AdminLogin)
+router.POST("/api/admin/password/forgot", handlers.AdminForgotPassword)
+router.POST("/api/admin/password/reset", handlers.AdminResetPassword)
+router.POST("/api/admin/login/2fa", handlers.AdminLoginVerify2FA)          // Second login step
+router.POST("/api/admin/login/2fa/setup", handlers.AdminLoginSetup2FA)     // Enrolment when 2FA is mandatory
 
 
 // ===== ADMIN PROTECTED ROUTES =====
 adminProtected := router.Group("/api/admin", handlers.AdminAuthMiddleware())
 {
     adminProtected.GET("/profile", handlers.GetAdminProfile)
+    adminProtected.PUT("/profile", handlers.AdminUpdateProfile)
+    adminProtected.PUT("/password", handlers.AdminChangePassword)
+
+    // Two-factor authentication
+    adminProtected.POST("/2fa/setup", handlers.AdminSetup2FA)
+    adminProtected.POST("/2fa/confirm", handlers.AdminConfirm2FA)
+    adminProtected.POST("/2fa/disable", handlers.AdminDisable2FA)
+    adminProtected.POST("/2fa/recovery-codes", handlers.AdminRegenerateRecoveryCodes)
+
+    // Admin account management
+    adminProtected.GET("/admins", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminListAdmins)
+    adminProtected.PUT("/admins/:id/role", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminChangeRole)
+    adminProtected.POST("/admins/:id/deactivate", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminDeactivateAdmin)
+    adminProtected.POST("/admins/:id/reactivate", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminReactivateAdmin)
+    adminProtected.POST("/admins/:id/force-password-reset", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminForcePasswordReset)
+    adminProtected.DELETE("/admins/:id", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminDeleteAdmin)
+
+    // Login history and lockouts
+    adminProtected.GET("/login-attempts", handlers.RequirePermission(handlers.PermManageAccounts), handlers.AdminListFailedLogins)
+    adminProtected.POST("/users/:id/unlock", handlers.RequirePermission(handlers.PermManageAccounts), handlers.AdminUnlockUser)
+    adminProtected.POST("/admins/:id/unlock", handlers.RequirePermission(handlers.PermManageAccounts), handlers.AdminUnlockAdmin)
+
+    // Contact form leads
+    adminProtected.GET("/inquiries", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminListInquiries)
+    adminProtected.GET("/inquiries/rejected", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminListContactRejections) // Submissions stopped by the spam checks
+    adminProtected.GET("/inquiries/:id", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminGetInquiry)
+    adminProtected.PUT("/inquiries/:id/status", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminUpdateInquiryStatus)
+    adminProtected.PUT("/inquiries/:id/assign", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminAssignInquiry)   // empty admin_id unassigns
+    adminProtected.POST("/inquiries/:id/notes", handlers.RequirePermission(handlers.PermManageInquiries), handlers.AdminAddInquiryNote)
+
+    // Admin invitations
+    adminProtected.POST("/invitations", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminCreateInvitation)
+    adminProtected.GET("/invitations", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminListInvitations)
+    adminProtected.DELETE("/invitations/:id", handlers.RequirePermission(handlers.PermManageAdmins), handlers.AdminRevokeInvitation)
+
+    // Settings (e.g. require_admin_2fa)
+    adminProtected.GET("/settings", handlers.RequirePermission(handlers.PermManageSettings), handlers.AdminGetSettings)
+    adminProtected.PUT("/settings", handlers.RequirePermission(handlers.PermManageSettings), handlers.AdminUpdateSettings)
     
     // Admin Model Management
-    adminProtected.GET("/models", handlers.AdminGetAllModels)           // Get all models (admin view)
-    adminProtected.GET("/models/:id", handlers.AdminGetModelById)       // Get specific model
-    adminProtected.POST("/models/:id/approve", handlers.AdminApproveModel)
-	adminProtected.PUT("/models/:id", handlers.AdminUpdateModel)  
-	adminProtected.POST("/models/:id/reject", handlers.AdminRejectModel)
-    adminProtected.DELETE("/models/:id", handlers.AdminDeleteModel)     // Admin can delete any model
+    adminProtected.GET("/models", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetAllModels)           // Get all models (admin view)
+    adminProtected.GET("/models/:id", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetModelById)       // Get specific model
+    adminProtected.POST("/models/:id/approve", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminApproveModel)
+	adminProtected.PUT("/models/:id", handlers.RequirePermission(handlers.PermEditTalents), handlers.AdminUpdateModel)  
+	adminProtected.POST("/models/:id/reject", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminRejectModel)
+	adminProtected.POST("/models/:id/review", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminReviewModel) // Mark as under review
+    adminProtected.DELETE("/models/:id", handlers.RequirePermission(handlers.PermDeleteTalents), handlers.AdminDeleteModel)     // Only super admins can delete
     
     // Admin Hostess Management  
-    adminProtected.GET("/hostesses", handlers.AdminGetAllHostesses)     // Get all hostesses (admin view)
-    adminProtected.GET("/hostesses/:id", handlers.AdminGetHostessById)  // Get specific hostess
-	 adminProtected.PUT("/hostesses/:id", handlers.AdminUpdateHostess) 
-    adminProtected.DELETE("/hostesses/:id", handlers.AdminDeleteHostess) // Admin can delete any hostess
-	adminProtected.POST("/hostesses/:id/approve", handlers.AdminApproveHostess)
-	adminProtected.POST("/hostesses/:id/reject", handlers.AdminRejectHostess)
+    adminProtected.GET("/hostesses", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetAllHostesses)     // Get all hostesses (admin view)
+    adminProtected.GET("/hostesses/:id", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetHostessById)  // Get specific hostess
+	 adminProtected.PUT("/hostesses/:id", handlers.RequirePermission(handlers.PermEditTalents), handlers.AdminUpdateHostess) 
+    adminProtected.DELETE("/hostesses/:id", handlers.RequirePermission(handlers.PermDeleteTalents), handlers.AdminDeleteHostess) // Only super admins can delete
+	adminProtected.POST("/hostesses/:id/approve", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminApproveHostess)
+	adminProtected.POST("/hostesses/:id/reject", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminRejectHostess)
+	adminProtected.POST("/hostesses/:id/review", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminReviewHostess) // Mark as under review
+
+    // Admin Talent Management, for any talent category
+    adminProtected.GET("/talents/:type", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetAllTalents)
+    adminProtected.GET("/talents/:type/:id", handlers.RequirePermission(handlers.PermViewTalents), handlers.AdminGetTalentById)
+    adminProtected.PUT("/talents/:type/:id", handlers.RequirePermission(handlers.PermEditTalents), handlers.AdminUpdateTalent)
+    adminProtected.DELETE("/talents/:type/:id", handlers.RequirePermission(handlers.PermDeleteTalents), handlers.AdminDeleteTalent)
+    adminProtected.POST("/talents/:type/:id/approve", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminApproveTalent)
+    adminProtected.POST("/talents/:type/:id/reject", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminRejectTalent)
+    adminProtected.POST("/talents/:type/:id/review", handlers.RequirePermission(handlers.PermReviewTalents), handlers.AdminReviewTalent)
 }
 
 // ===== STATIC ROUTES =====
@@ -101,6 +206,14 @@ router.Static("/uploads", "./uploads") // so uploaded files can be accessed
 	
 	db := &database.Database{DB: database.DB}
 	db.InitDatabase()
+	database.FinishLegacyTalentMigration() // Drops the pre-talents tables only when DROP_LEGACY_TALENT_TABLES=true
+	if err := handlers.LoadTalentTypes(); err != nil { // Talent categories beyond models and hostesses
+		log.Fatal("Talent configuration error: ", err)
+	}
+	handlers.BootstrapSuperAdmin()
+	handlers.NormalizeStoredPhoneNumbers()
+	handlers.EncryptStoredTOTPSecrets()
+	outbox.Start() // Deliver queued emails in the background
 	router.GET("/api/", func(context *gin.Context) {
 		context.JSON(http.StatusOK, gin.H{
 			"message": "Welcome to the api",
@@ -1,19 +1,19 @@
 package middlewares
 
 import (
-	"fmt"
+	"database/sql"
+	"models/auth"
+	"models/database"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-) 
+)
 
-var jwtSecret = []byte(os.Getenv("JWT_SECRET"))
+// Sessions record when they were last used at most once per this interval
+const lastSeenResolution = time.Minute
 
-// AuthMiddleware checks the Authorization header for a valid JWT token
+// AuthMiddleware checks the Authorization header for a valid user access token
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -25,44 +25,63 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		// Expecting "Bearer <token>"
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
+		tokenString, ok := auth.BearerToken(authHeader)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
 			c.Abort()
 			return
 		}
 
-		// Parse and verify token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		// Parse and verify token (signature, expiry and audience)
+		claims, err := auth.Verify(tokenString, auth.AudienceUser)
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
+		userID, err := claims.UserID()
+		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
 		}
 
-		// Optional: check expiry manually
-		if exp, ok := claims["exp"].(float64); ok && float64(time.Now().Unix()) > exp {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+		// Reject tokens whose session was logged out or revoked
+		sessionID := claims.SessionID
+		if sessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		var lastSeen time.Time
+		err = database.DB.QueryRow(`
+			SELECT last_used_at FROM sessions
+			WHERE id = $1 AND subject_type = 'user' AND user_id = $2
+			  AND revoked_at IS NULL AND expires_at > NOW()
+		`, sessionID, userID).Scan(&lastSeen)
+		if err == sql.ErrNoRows {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+			c.Abort()
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 			c.Abort()
 			return
 		}
 
+		// Keep last-seen time and IP roughly current without writing on every request
+		if time.Since(lastSeen) > lastSeenResolution {
+			database.DB.Exec(`
+				UPDATE sessions SET last_used_at = NOW(), ip_address = $2 WHERE id = $1
+			`, sessionID, c.ClientIP())
+		}
+
 		// Save user info in context
-		c.Set("user_id", claims["user_id"])
-		c.Set("email", claims["email"])
+		c.Set("user_id", userID)
+		c.Set("email", claims.Email)
+		c.Set("session_id", sessionID)
 
 		c.Next()
 	}
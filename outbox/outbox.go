@@ -0,0 +1,210 @@
+// Package outbox queues outgoing email in the database and delivers it in the background.
+//
+// Handlers call Enqueue inside the transaction that makes the change the email is about,
+// so a message is stored if and only if the change is committed. A worker started with
+// Start sends due messages, retrying failures with exponential backoff until maxAttempts,
+// after which the message is left in the dead state for an operator to look at. Only the
+// envelope of sent and dead messages is kept; their body is dropped.
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"models/database"
+	"models/mailer"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusDead    = "dead"
+)
+
+const (
+	maxAttempts  = 8
+	firstBackoff = 30 * time.Second
+	maxBackoff   = 6 * time.Hour
+	pollInterval = 2 * time.Second
+	batchSize    = 20
+	// A message claimed by a worker that never reports back (crash, restart) is retried after the lease
+	sendLease = 2 * time.Minute
+)
+
+// Execer is satisfied by both *sql.DB and *sql.Tx
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Enqueue stores msg for delivery. key identifies the email: a second Enqueue with the same
+// key is ignored, so retrying a request never sends the same email twice.
+func Enqueue(q Execer, key string, msg mailer.Message) error {
+	return EnqueueExpiring(q, key, msg, time.Time{})
+}
+
+// EnqueueExpiring is Enqueue for a message that is useless after expiresAt, such as one
+// carrying a sign-in code. If it has not been sent by then it is given up on and its
+// body is dropped. A zero expiresAt never expires.
+func EnqueueExpiring(q Execer, key string, msg mailer.Message, expiresAt time.Time) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("outbox: message %s has no recipient", key)
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	var expires sql.NullTime
+	if !expiresAt.IsZero() {
+		expires = sql.NullTime{Time: expiresAt, Valid: true}
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO email_outbox (idempotency_key, message, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key) DO NOTHING
+	`, key, payload, expires)
+	return err
+}
+
+// Start runs the delivery worker in the background. It must run after the database is connected.
+func Start() {
+	go func() {
+		for {
+			// A full batch means more messages are waiting, so only pause after a partial one
+			if deliverDue() < batchSize {
+				time.Sleep(pollInterval)
+			}
+		}
+	}()
+}
+
+type queued struct {
+	id       string
+	key      string
+	attempts int
+	msg      mailer.Message
+}
+
+// deliverDue claims a batch of due messages, sends them and returns how many were claimed
+func deliverDue() int {
+	expireStale()
+	batch, err := claimDue()
+	if err != nil {
+		log.Println("Outbox: failed to claim messages:", err)
+		return 0
+	}
+
+	for _, m := range batch {
+		// The row id is stable across retries, so providers see the same Message-ID / CustomID
+		m.msg.ID = m.id
+		if err := mailer.Send(m.msg); err != nil {
+			recordFailure(m, err)
+			continue
+		}
+		// Sent messages keep their envelope but drop the body, which may hold sign-in codes
+		_, err := database.DB.Exec(`
+			UPDATE email_outbox SET status = $2, sent_at = NOW(), locked_until = NULL, last_error = NULL,
+			    message = message - 'Text' - 'HTML'
+			WHERE id = $1
+		`, m.id, StatusSent)
+		if err != nil {
+			log.Printf("Outbox: message %s was sent but could not be marked as sent: %v", m.key, err)
+		}
+	}
+	return len(batch)
+}
+
+// expireStale gives up on unsent messages past their expiry. Their codes no longer
+// work, so the body is dropped rather than kept around in the table.
+func expireStale() {
+	res, err := database.DB.Exec(`
+		UPDATE email_outbox SET status = $1, locked_until = NULL, last_error = 'expired before it could be sent',
+		    message = message - 'Text' - 'HTML'
+		WHERE expires_at < NOW()
+		  AND (status = $2 OR (status = $3 AND locked_until < NOW()))
+	`, StatusDead, StatusPending, StatusSending)
+	if err != nil {
+		log.Println("Outbox: failed to expire messages:", err)
+		return
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("Outbox: %d message(s) expired before they could be sent", n)
+	}
+}
+
+// claimDue marks due messages as sending so that concurrent workers skip them
+func claimDue() ([]queued, error) {
+	rows, err := database.DB.Query(`
+		UPDATE email_outbox SET status = $1, attempts = attempts + 1, locked_until = NOW() + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE (status = $3 AND next_attempt_at <= NOW())
+			   OR (status = $1 AND locked_until < NOW())
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, idempotency_key, attempts, message
+	`, StatusSending, int(sendLease.Seconds()), StatusPending, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []queued
+	for rows.Next() {
+		var (
+			m       queued
+			payload []byte
+		)
+		if err := rows.Scan(&m.id, &m.key, &m.attempts, &payload); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &m.msg); err != nil {
+			recordFailure(m, fmt.Errorf("unreadable message: %v", err))
+			continue
+		}
+		batch = append(batch, m)
+	}
+	return batch, rows.Err()
+}
+
+func recordFailure(m queued, sendErr error) {
+	if m.attempts >= maxAttempts {
+		log.Printf("Outbox: giving up on message %s after %d attempts: %v", m.key, m.attempts, sendErr)
+		// The envelope and error are kept for the operator; the body may hold sign-in codes
+		_, err := database.DB.Exec(`
+			UPDATE email_outbox SET status = $2, locked_until = NULL, last_error = $3,
+			    message = message - 'Text' - 'HTML'
+			WHERE id = $1
+		`, m.id, StatusDead, sendErr.Error())
+		if err != nil {
+			log.Printf("Outbox: failed to mark message %s as dead: %v", m.key, err)
+		}
+		return
+	}
+
+	wait := backoff(m.attempts)
+	log.Printf("Outbox: attempt %d for message %s failed, retrying in %s: %v", m.attempts, m.key, wait, sendErr)
+	_, err := database.DB.Exec(`
+		UPDATE email_outbox SET status = $2, locked_until = NULL, last_error = $3, next_attempt_at = $4 WHERE id = $1
+	`, m.id, StatusPending, sendErr.Error(), time.Now().Add(wait))
+	if err != nil {
+		log.Printf("Outbox: failed to reschedule message %s: %v", m.key, err)
+	}
+}
+
+// backoff is the wait after the given number of failed attempts: 30s, 1m, 2m, ... capped at maxBackoff
+func backoff(attempts int) time.Duration {
+	wait := firstBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
@@ -0,0 +1,204 @@
+// Package phone normalizes phone numbers to E.164 (+<country code><number>) and
+// checks them against the numbering plan of their country.
+package phone
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// Region describes the national numbering plan of one country
+type Region struct {
+	Code        string // ISO 3166-1 alpha-2
+	CallingCode string
+	// TrunkPrefix is dialled before national numbers and dropped in international
+	// format, e.g. the 0 in French 06 12 34 56 78. Empty when the leading 0 is part of the number.
+	TrunkPrefix string
+	// Lengths lists the valid lengths of the national number, without trunk prefix
+	Lengths []int
+	// LeadingDigits lists the digits a national number may start with; empty allows any
+	LeadingDigits string
+}
+
+var regions = map[string]Region{
+	// West and Central Africa
+	"CI": {Code: "CI", CallingCode: "225", Lengths: []int{10}, LeadingDigits: "02"},
+	"SN": {Code: "SN", CallingCode: "221", Lengths: []int{9}, LeadingDigits: "37"},
+	"ML": {Code: "ML", CallingCode: "223", Lengths: []int{8}},
+	"BF": {Code: "BF", CallingCode: "226", Lengths: []int{8}},
+	"NE": {Code: "NE", CallingCode: "227", Lengths: []int{8}},
+	"TG": {Code: "TG", CallingCode: "228", Lengths: []int{8}},
+	"BJ": {Code: "BJ", CallingCode: "229", Lengths: []int{8, 10}},
+	"GN": {Code: "GN", CallingCode: "224", Lengths: []int{8, 9}},
+	"GH": {Code: "GH", CallingCode: "233", TrunkPrefix: "0", Lengths: []int{9}},
+	"NG": {Code: "NG", CallingCode: "234", TrunkPrefix: "0", Lengths: []int{8, 10}},
+	"CM": {Code: "CM", CallingCode: "237", Lengths: []int{9}, LeadingDigits: "26"},
+	"GA": {Code: "GA", CallingCode: "241", TrunkPrefix: "0", Lengths: []int{7, 8}},
+	"CG": {Code: "CG", CallingCode: "242", Lengths: []int{9}},
+	"CD": {Code: "CD", CallingCode: "243", TrunkPrefix: "0", Lengths: []int{9}},
+	// North and East Africa
+	"MA": {Code: "MA", CallingCode: "212", TrunkPrefix: "0", Lengths: []int{9}},
+	"DZ": {Code: "DZ", CallingCode: "213", TrunkPrefix: "0", Lengths: []int{8, 9}},
+	"TN": {Code: "TN", CallingCode: "216", Lengths: []int{8}},
+	"KE": {Code: "KE", CallingCode: "254", TrunkPrefix: "0", Lengths: []int{9}},
+	"ZA": {Code: "ZA", CallingCode: "27", TrunkPrefix: "0", Lengths: []int{9}},
+	// Europe
+	"FR": {Code: "FR", CallingCode: "33", TrunkPrefix: "0", Lengths: []int{9}},
+	"BE": {Code: "BE", CallingCode: "32", TrunkPrefix: "0", Lengths: []int{8, 9}},
+	"CH": {Code: "CH", CallingCode: "41", TrunkPrefix: "0", Lengths: []int{9}},
+	"LU": {Code: "LU", CallingCode: "352", Lengths: []int{6, 7, 8, 9, 10, 11}},
+	"GB": {Code: "GB", CallingCode: "44", TrunkPrefix: "0", Lengths: []int{9, 10}},
+	"DE": {Code: "DE", CallingCode: "49", TrunkPrefix: "0", Lengths: []int{6, 7, 8, 9, 10, 11}},
+	"ES": {Code: "ES", CallingCode: "34", Lengths: []int{9}},
+	"IT": {Code: "IT", CallingCode: "39", Lengths: []int{6, 7, 8, 9, 10, 11}},
+	"PT": {Code: "PT", CallingCode: "351", Lengths: []int{9}},
+	"NL": {Code: "NL", CallingCode: "31", TrunkPrefix: "0", Lengths: []int{9}},
+	// Americas and Middle East
+	"US": {Code: "US", CallingCode: "1", TrunkPrefix: "1", Lengths: []int{10}, LeadingDigits: "2-9"},
+	"CA": {Code: "CA", CallingCode: "1", TrunkPrefix: "1", Lengths: []int{10}, LeadingDigits: "2-9"},
+	"AE": {Code: "AE", CallingCode: "971", TrunkPrefix: "0", Lengths: []int{8, 9}},
+}
+
+// defaultRegionCode is used when PHONE_DEFAULT_REGION is unset. Its 10-digit
+// numbers (07..., 05..., 01...) are the format the API accepted before
+// international numbers were supported.
+const defaultRegionCode = "CI"
+
+var (
+	ErrEmpty         = errors.New("phone number is empty")
+	ErrInvalid       = errors.New("phone number is not valid")
+	ErrUnknownRegion = errors.New("unknown phone region")
+)
+
+// DefaultRegion is the region assumed for numbers written without a country code
+func DefaultRegion() string {
+	if code := strings.ToUpper(strings.TrimSpace(os.Getenv("PHONE_DEFAULT_REGION"))); code != "" {
+		return code
+	}
+	return defaultRegionCode
+}
+
+// Normalize returns the E.164 form of raw. Numbers starting with + or 00 are
+// read as international; anything else is read as a national number of region
+// (DefaultRegion when empty). Spaces, dots, dashes and parentheses are ignored.
+func Normalize(raw, region string) (string, error) {
+	digits, international := clean(raw)
+	if digits == "" {
+		return "", ErrEmpty
+	}
+
+	if international {
+		return normalizeInternational(digits)
+	}
+
+	if region == "" {
+		region = DefaultRegion()
+	}
+	r, ok := regions[strings.ToUpper(region)]
+	if !ok {
+		return "", ErrUnknownRegion
+	}
+
+	national := digits
+	if r.TrunkPrefix != "" && strings.HasPrefix(national, r.TrunkPrefix) && !r.validNational(national) {
+		national = strings.TrimPrefix(national, r.TrunkPrefix)
+	}
+	if !r.validNational(national) {
+		return "", ErrInvalid
+	}
+	return "+" + r.CallingCode + national, nil
+}
+
+// IsE164 reports whether s is already a normalized number
+func IsE164(s string) bool {
+	if !strings.HasPrefix(s, "+") {
+		return false
+	}
+	n, err := normalizeInternational(s[1:])
+	return err == nil && n == s
+}
+
+// clean drops formatting characters and reports whether the number was written in international form
+func clean(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+
+	var b strings.Builder
+	for _, ch := range raw {
+		switch {
+		case ch >= '0' && ch <= '9':
+			b.WriteRune(ch)
+		case ch == ' ', ch == '-', ch == '.', ch == '(', ch == ')', ch == '/':
+		case ch == '+' && b.Len() == 0:
+		default:
+			return "", false
+		}
+	}
+
+	digits := b.String()
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	}
+	return digits, international
+}
+
+func normalizeInternational(digits string) (string, error) {
+	// E.164 allows at most 15 digits including the country code
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", ErrInvalid
+	}
+
+	// Calling codes are prefix-free, so the first match is the only one
+	for size := 1; size <= 3; size++ {
+		code := digits[:size]
+		national := digits[size:]
+		matched := false
+		for _, r := range regions {
+			if r.CallingCode != code {
+				continue
+			}
+			matched = true
+			if r.validNational(national) {
+				return "+" + digits, nil
+			}
+		}
+		if matched {
+			return "", ErrInvalid
+		}
+	}
+
+	// Countries without a plan here only get the E.164 length check
+	return "+" + digits, nil
+}
+
+func (r Region) validNational(national string) bool {
+	lengthOK := false
+	for _, n := range r.Lengths {
+		if len(national) == n {
+			lengthOK = true
+			break
+		}
+	}
+	if !lengthOK {
+		return false
+	}
+	return r.LeadingDigits == "" || inDigitSet(national[0], r.LeadingDigits)
+}
+
+// inDigitSet checks d against a set written like "26" or "2-9"
+func inDigitSet(d byte, set string) bool {
+	for i := 0; i < len(set); i++ {
+		if i+2 < len(set) && set[i+1] == '-' {
+			if d >= set[i] && d <= set[i+2] {
+				return true
+			}
+			i += 2
+			continue
+		}
+		if d == set[i] {
+			return true
+		}
+	}
+	return false
+}
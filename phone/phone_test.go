@@ -0,0 +1,74 @@
+package phone
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		region  string
+		want    string
+		wantErr error
+	}{
+		{name: "national in default region", raw: "07 07 12 34 56", want: "+2250707123456"},
+		{name: "international with plus", raw: "+225 07 07 12 34 56", want: "+2250707123456"},
+		{name: "international with 00", raw: "00225 0707123456", want: "+2250707123456"},
+		{name: "trunk prefix dropped", raw: "06 12 34 56 78", region: "FR", want: "+33612345678"},
+		{name: "region is case insensitive", raw: "06.12.34.56.78", region: "fr", want: "+33612345678"},
+		{name: "international without trunk prefix", raw: "+33 6 12 34 56 78", want: "+33612345678"},
+		{name: "international keeping trunk prefix", raw: "+33 06 12 34 56 78", wantErr: ErrInvalid},
+		{name: "formatting characters", raw: "(202) 555-0123", region: "US", want: "+12025550123"},
+		{name: "north american trunk prefix", raw: "1 202 555 0123", region: "US", want: "+12025550123"},
+		{name: "leading digit outside the plan", raw: "+1 123 555 0123", wantErr: ErrInvalid},
+		{name: "leading digit in a set", raw: "77 123 45 67", region: "SN", want: "+221771234567"},
+		{name: "leading digit not in the set", raw: "57 123 45 67", region: "SN", wantErr: ErrInvalid},
+		{name: "wrong national length", raw: "0707 1234", region: "CI", wantErr: ErrInvalid},
+		{name: "country without a plan", raw: "+999 1234 5678", want: "+99912345678"},
+		{name: "international too short", raw: "+33 1234", wantErr: ErrInvalid},
+		{name: "international too long", raw: "+33 6123456789012345", wantErr: ErrInvalid},
+		{name: "empty", raw: "  ", wantErr: ErrEmpty},
+		{name: "unknown region", raw: "0707123456", region: "XX", wantErr: ErrUnknownRegion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PHONE_DEFAULT_REGION", "")
+			got, err := Normalize(tt.raw, tt.region)
+			if err != tt.wantErr {
+				t.Fatalf("Normalize(%q, %q) error = %v, want %v", tt.raw, tt.region, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDefaultRegionFromEnv(t *testing.T) {
+	t.Setenv("PHONE_DEFAULT_REGION", " fr ")
+	got, err := Normalize("06 12 34 56 78", "")
+	if err != nil || got != "+33612345678" {
+		t.Fatalf("Normalize() = %q, %v, want +33612345678", got, err)
+	}
+}
+
+func TestIsE164(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"+2250707123456", true},
+		{"+33612345678", true},
+		{"+99912345678", true},
+		{"2250707123456", false},
+		{"+225 0707123456", false},
+		{"+330612345678", false},
+		{"+", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsE164(tt.in); got != tt.want {
+			t.Errorf("IsE164(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Package sms sends text messages through a configurable provider.
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sender delivers one text message to an E.164 phone number
+type Sender interface {
+	Send(to, message string) error
+}
+
+// The driver is chosen with SMS_DRIVER:
+//
+//	log   print messages to the server log (default, for development)
+//	file  append messages to SMS_FILE_PATH (default sms_outbox.log)
+//
+// A real provider plugs in by implementing Sender and adding a case to newSender.
+var sender Sender
+
+var ErrNotInitialized = errors.New("sms: no provider configured, call sms.Init first")
+
+// Init selects the provider from the environment. It must run after the .env file is loaded.
+func Init() error {
+	s, err := newSender(strings.ToLower(strings.TrimSpace(os.Getenv("SMS_DRIVER"))))
+	if err != nil {
+		return err
+	}
+	sender = s
+	return nil
+}
+
+func newSender(driver string) (Sender, error) {
+	switch driver {
+	case "", "log":
+		return logSender{}, nil
+	case "file":
+		path := os.Getenv("SMS_FILE_PATH")
+		if path == "" {
+			path = "sms_outbox.log"
+		}
+		return &fileSender{path: path}, nil
+	}
+	return nil, fmt.Errorf("unknown SMS_DRIVER %q, expected log or file", driver)
+}
+
+// Send delivers a message with the configured provider
+func Send(to, message string) error {
+	if sender == nil {
+		return ErrNotInitialized
+	}
+	return sender.Send(to, message)
+}
+
+type logSender struct{}
+
+func (logSender) Send(to, message string) error {
+	log.Printf("SMS to %s: %s", to, message)
+	return nil
+}
+
+type fileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func (f *fileSender) Send(to, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%s\tto=%s\t%s\n", time.Now().Format(time.RFC3339), to, message)
+	return err
+}